@@ -0,0 +1,20 @@
+module contracts
+
+go 1.25.0
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/hamba/avro/v2 v2.29.0
+	github.com/stretchr/testify v1.11.1
+	google.golang.org/protobuf v1.36.8
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-viper/mapstructure/v2 v2.2.1 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,188 @@
+// Package membroker is an in-process message broker with Kafka like semantics:
+// topics split into partitions by message key, and consumer groups that share
+// the partitions of a topic and keep an offset per partition. Messages are
+// kept in memory for the life of the broker.
+//
+// It lives next to the event contracts so products and notifications can share
+// one broker, which lets the whole event pipeline run inside a single process.
+package membroker
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+var ErrClosed = errors.New("broker is closed")
+
+// DefaultPartitions is the partition count of topics when none is configured.
+const DefaultPartitions = 3
+
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Time      time.Time
+}
+
+type Broker struct {
+	partitions int
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	closed bool
+}
+
+type topic struct {
+	partitions [][]*Message
+	groups     map[string]*group
+	// changed is closed and replaced when messages arrive or group membership changes.
+	changed chan struct{}
+}
+
+type group struct {
+	offsets []int64
+	// owners holds the member consuming each partition, nil when unassigned.
+	owners  []*member
+	members []*member
+}
+
+// member is a Consume call in a group. It must not be zero sized, pointers to
+// zero sized values may be equal.
+type member struct {
+	_ byte
+}
+
+// New creates a broker whose topics have the given number of partitions,
+// DefaultPartitions when it's not positive.
+func New(partitions int) *Broker {
+	if partitions <= 0 {
+		partitions = DefaultPartitions
+	}
+	return &Broker{
+		partitions: partitions,
+		topics:     map[string]*topic{},
+	}
+}
+
+// Send appends a message to a partition of topic chosen by key. Messages
+// without a key are spread over the partitions.
+func (b *Broker) Send(ctx context.Context, topicName string, message, key []byte, headers map[string]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	t := b.topic(topicName)
+	partition := b.partition(t, key)
+	msg := &Message{
+		Topic:     topicName,
+		Partition: partition,
+		Offset:    int64(len(t.partitions[partition])),
+		Key:       key,
+		Value:     message,
+		Headers:   headers,
+		Time:      time.Now(),
+	}
+	t.partitions[partition] = append(t.partitions[partition], msg)
+	t.notify()
+	return nil
+}
+
+// Ping fails once the broker is closed.
+func (b *Broker) Ping(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Close stops all consumers. Sending to a closed broker fails.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		for _, t := range b.topics {
+			t.notify()
+		}
+	}
+	return nil
+}
+
+// Messages returns the messages of topic in partition order, for tests and inspection.
+func (b *Broker) Messages(topicName string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []*Message
+	if t, ok := b.topics[topicName]; ok {
+		for _, partition := range t.partitions {
+			messages = append(messages, partition...)
+		}
+	}
+	return messages
+}
+
+// Offsets returns the committed offsets of groupName on topic by partition.
+func (b *Broker) Offsets(topicName, groupName string) []int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]int64(nil), b.topic(topicName).group(groupName).offsets...)
+}
+
+// topic returns the topic, creating it on first use. b.mu must be held.
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{
+			partitions: make([][]*Message, b.partitions),
+			groups:     map[string]*group{},
+			changed:    make(chan struct{}),
+		}
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *Broker) partition(t *topic, key []byte) int {
+	if len(key) == 0 {
+		var total int
+		for _, p := range t.partitions {
+			total += len(p)
+		}
+		return total % len(t.partitions)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(len(t.partitions)))
+}
+
+func (t *topic) notify() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+func (t *topic) group(name string) *group {
+	g, ok := t.groups[name]
+	if !ok {
+		g = &group{
+			offsets: make([]int64, len(t.partitions)),
+			owners:  make([]*member, len(t.partitions)),
+		}
+		t.groups[name] = g
+	}
+	return g
+}
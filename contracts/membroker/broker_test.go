@@ -0,0 +1,197 @@
+package membroker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector records handled messages and signals once it has seen want of them.
+type collector struct {
+	mu       sync.Mutex
+	messages []*Message
+	want     int
+	done     chan struct{}
+}
+
+func newCollector(want int) *collector {
+	return &collector{want: want, done: make(chan struct{})}
+}
+
+func (c *collector) handle(ctx context.Context, msg *Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+	if len(c.messages) == c.want {
+		close(c.done)
+	}
+	return nil
+}
+
+func (c *collector) wait(t *testing.T) []*Message {
+	t.Helper()
+	select {
+	case <-c.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %d messages", c.want)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Message(nil), c.messages...)
+}
+
+// consume runs Consume in the background until the test ends.
+func consume(t *testing.T, broker *Broker, group string, handler Handler) context.CancelFunc {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = broker.Consume(ctx, "events", group, 2, handler)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return cancel
+}
+
+func send(t *testing.T, broker *Broker, key string, n int) {
+	t.Helper()
+	for i := range n {
+		require.NoError(t, broker.Send(context.Background(), "events", []byte(fmt.Sprintf("%s-%d", key, i)), []byte(key), map[string]string{"n": fmt.Sprint(i)}))
+	}
+}
+
+func TestBrokerOrdersMessagesOfAKey(t *testing.T) {
+	broker := New(3)
+	send(t, broker, "a", 5)
+	send(t, broker, "b", 5)
+
+	c := newCollector(10)
+	consume(t, broker, "group", c.handle)
+	messages := c.wait(t)
+
+	var a []string
+	for _, msg := range messages {
+		if string(msg.Key) == "a" {
+			a = append(a, string(msg.Value))
+		}
+	}
+	assert.Equal(t, []string{"a-0", "a-1", "a-2", "a-3", "a-4"}, a)
+
+	partitions := map[string]int{}
+	for _, msg := range broker.Messages("events") {
+		partitions[string(msg.Key)] = msg.Partition
+	}
+	for _, msg := range messages {
+		assert.Equal(t, partitions[string(msg.Key)], msg.Partition, "Messages of a key should share a partition")
+	}
+}
+
+func TestBrokerGroups(t *testing.T) {
+	broker := New(3)
+
+	first, second := newCollector(20), newCollector(20)
+	consume(t, broker, "first", first.handle)
+	consume(t, broker, "second", second.handle)
+	for i := range 10 {
+		send(t, broker, fmt.Sprint("key-", i), 2)
+	}
+
+	assert.Len(t, first.wait(t), 20, "Every group should see every message")
+	assert.Len(t, second.wait(t), 20)
+}
+
+func TestBrokerGroupMembersSplitPartitions(t *testing.T) {
+	broker := New(4)
+
+	var mu sync.Mutex
+	seen := map[int]map[int]bool{}
+	all := newCollector(40)
+	handler := func(member int) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			mu.Lock()
+			if seen[msg.Partition] == nil {
+				seen[msg.Partition] = map[int]bool{}
+			}
+			seen[msg.Partition][member] = true
+			mu.Unlock()
+			return all.handle(ctx, msg)
+		}
+	}
+
+	consume(t, broker, "group", handler(1))
+	consume(t, broker, "group", handler(2))
+	// Let both members join before anything is sent, so no partition moves.
+	require.Eventually(t, func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return len(broker.topic("events").group("group").members) == 2
+	}, time.Second, time.Millisecond)
+	for i := range 20 {
+		send(t, broker, fmt.Sprint("key-", i), 2)
+	}
+
+	assert.Len(t, all.wait(t), 40, "Each message should be handled once in a group")
+	members := map[int]bool{}
+	for partition, by := range seen {
+		assert.Len(t, by, 1, "Partition %d should be consumed by one member", partition)
+		for member := range by {
+			members[member] = true
+		}
+	}
+	assert.Len(t, members, 2, "Both members should get partitions")
+}
+
+func TestBrokerResumesFromCommittedOffsets(t *testing.T) {
+	broker := New(1)
+	send(t, broker, "a", 3)
+
+	c := newCollector(3)
+	cancel := consume(t, broker, "group", c.handle)
+	c.wait(t)
+	cancel()
+	require.Eventually(t, func() bool {
+		return broker.Offsets("events", "group")[0] == 3
+	}, time.Second, time.Millisecond)
+
+	send(t, broker, "a", 2)
+	resumed := newCollector(2)
+	consume(t, broker, "group", resumed.handle)
+
+	messages := resumed.wait(t)
+	assert.Equal(t, int64(3), messages[0].Offset, "Committed messages should not be handled again")
+	assert.Equal(t, "0", messages[0].Headers["n"])
+}
+
+func TestBrokerClose(t *testing.T) {
+	broker := New(1)
+
+	done := make(chan error)
+	go func() {
+		done <- broker.Consume(context.Background(), "events", "group", 1, func(ctx context.Context, msg *Message) error { return nil })
+	}()
+	require.Eventually(t, func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return len(broker.topic("events").group("group").members) == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, broker.Close())
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Consume should return once the broker is closed")
+	}
+	assert.ErrorIs(t, broker.Send(context.Background(), "events", nil, nil, nil), ErrClosed)
+	assert.ErrorIs(t, broker.Ping(context.Background()), ErrClosed)
+}
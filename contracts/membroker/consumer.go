@@ -0,0 +1,143 @@
+package membroker
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Handler processes a message. Like a Kafka consumer with auto commit, the
+// offset is committed whether the handler fails or not.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Consume joins groupName on topic and calls handler with the messages of the
+// partitions assigned to this member, until ctx is done or the broker is
+// closed. The members of a group split the partitions of the topic between
+// them, each group sees every message. Messages of a partition are handled in
+// order, up to workerCount partitions concurrently.
+func (b *Broker) Consume(ctx context.Context, topicName, groupName string, workerCount int, handler Handler) error {
+	m := &member{}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	t := b.topic(topicName)
+	g := t.group(groupName)
+	g.members = append(g.members, m)
+	t.notify()
+	b.mu.Unlock()
+
+	defer b.leave(t, g, m)
+
+	workers := make(chan struct{}, max(workerCount, 1))
+	for {
+		batches, changed, ok := b.poll(t, g, m)
+		if !ok {
+			return nil
+		}
+		if len(batches) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-changed:
+				continue
+			}
+		}
+
+		var wg sync.WaitGroup
+		for _, batch := range batches {
+			workers <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-workers
+					wg.Done()
+				}()
+				for _, msg := range batch {
+					if ctx.Err() != nil {
+						return
+					}
+					_ = handler(ctx, msg)
+					b.commit(g, m, msg)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// poll rebalances the partitions of g and returns the unconsumed messages of
+// the ones m owns, grouped by partition. ok is false once the broker is closed.
+func (b *Broker) poll(t *topic, g *group, m *member) (batches [][]*Message, changed <-chan struct{}, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, false
+	}
+
+	if g.rebalance(m) {
+		t.notify()
+	}
+
+	for p, owner := range g.owners {
+		if owner == m && g.offsets[p] < int64(len(t.partitions[p])) {
+			batches = append(batches, slices.Clone(t.partitions[p][g.offsets[p]:]))
+		}
+	}
+	return batches, t.changed, true
+}
+
+// rebalance makes m give up partitions beyond its fair share and claim
+// unassigned ones up to it. It reports whether partitions were released, so
+// the other members get to claim them.
+func (g *group) rebalance(m *member) (released bool) {
+	share := (len(g.owners) + len(g.members) - 1) / len(g.members)
+
+	var owned int
+	for p, owner := range g.owners {
+		if owner != m {
+			continue
+		}
+		if owned < share {
+			owned++
+			continue
+		}
+		g.owners[p] = nil
+		released = true
+	}
+
+	for p, owner := range g.owners {
+		if owned < share && owner == nil {
+			g.owners[p] = m
+			owned++
+		}
+	}
+	return released
+}
+
+func (b *Broker) commit(g *group, m *member, msg *Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if g.owners[msg.Partition] == m && g.offsets[msg.Partition] == msg.Offset {
+		g.offsets[msg.Partition] = msg.Offset + 1
+	}
+}
+
+func (b *Broker) leave(t *topic, g *group, m *member) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g.members = slices.DeleteFunc(g.members, func(other *member) bool { return other == m })
+	for p, owner := range g.owners {
+		if owner == m {
+			g.owners[p] = nil
+		}
+	}
+	t.notify()
+}
@@ -0,0 +1,111 @@
+package productevent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hamba/avro/v2"
+)
+
+// AvroContentType is the content type of Avro encoded events.
+const AvroContentType = "application/avro"
+
+var parsedAvroSchema = avro.MustParse(AvroSchema)
+
+type avroEvent struct {
+	ID            string      `avro:"id"`
+	Source        string      `avro:"source"`
+	Type          string      `avro:"type"`
+	Subject       string      `avro:"subject"`
+	Time          time.Time   `avro:"time"`
+	DataSchema    string      `avro:"data_schema"`
+	TenantID      string      `avro:"tenant_id"`
+	CorrelationID string      `avro:"correlation_id"`
+	Product       avroProduct `avro:"product"`
+	Actor         string      `avro:"actor"`
+}
+
+type avroProduct struct {
+	ID          string    `avro:"id"`
+	Name        string    `avro:"name"`
+	Description string    `avro:"description"`
+	Price       int64     `avro:"price"`
+	CreatedAt   time.Time `avro:"created_at"`
+}
+
+// EncodeAvro encodes e with AvroSchema.
+func EncodeAvro(e *Event) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	return avro.Marshal(parsedAvroSchema, &avroEvent{
+		ID:            e.ID,
+		Source:        e.Source,
+		Type:          e.Type.CloudEventType(),
+		Subject:       e.Subject,
+		Time:          e.Time,
+		DataSchema:    e.DataSchema,
+		TenantID:      e.TenantID,
+		CorrelationID: e.CorrelationID,
+		Product: avroProduct{
+			ID:          e.Product.ID,
+			Name:        e.Product.Name,
+			Description: e.Product.Description,
+			Price:       int64(e.Product.Price),
+			CreatedAt:   e.Product.CreatedAt,
+		},
+		Actor: e.Actor,
+	})
+}
+
+// AvroDecoder decodes events written with a given writer schema.
+type AvroDecoder struct {
+	schema avro.Schema
+}
+
+// NewAvroDecoder resolves the writer schema events were encoded with against
+// AvroSchema, so fields the writer doesn't know get their defaults and fields
+// AvroSchema doesn't know are skipped.
+func NewAvroDecoder(writerSchema string) (*AvroDecoder, error) {
+	writer, err := avro.Parse(writerSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	schema, err := avro.NewSchemaCompatibility().Resolve(parsedAvroSchema, writer)
+	if err != nil {
+		return nil, fmt.Errorf("avro schema can't be read: %w", err)
+	}
+	return &AvroDecoder{schema: schema}, nil
+}
+
+func (d *AvroDecoder) Decode(payload []byte) (*Event, error) {
+	var record avroEvent
+	if err := avro.Unmarshal(d.schema, payload, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode avro event: %w", err)
+	}
+
+	eventType, err := parseType(record.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return validated(&Event{
+		ID:            record.ID,
+		Source:        record.Source,
+		Type:          eventType,
+		Subject:       record.Subject,
+		Time:          record.Time.UTC(),
+		DataSchema:    record.DataSchema,
+		TenantID:      record.TenantID,
+		CorrelationID: record.CorrelationID,
+		Product: Product{
+			ID:          record.Product.ID,
+			Name:        record.Product.Name,
+			Description: record.Product.Description,
+			Price:       Cents(record.Product.Price),
+			CreatedAt:   record.Product.CreatedAt.UTC(),
+		},
+		Actor: record.Actor,
+	})
+}
@@ -0,0 +1,84 @@
+package productevent_test
+
+import (
+	"contracts/productevent"
+	"contracts/productevent/eventtest"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// withoutAvroFields returns AvroSchema without the named top level fields, the
+// way an older producer's schema looks.
+func withoutAvroFields(t *testing.T, names ...string) string {
+	t.Helper()
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(productevent.AvroSchema), &schema))
+
+	var fields []any
+	for _, field := range schema["fields"].([]any) {
+		name := field.(map[string]any)["name"].(string)
+		if !slices.Contains(names, name) {
+			fields = append(fields, field)
+		}
+	}
+	schema["fields"] = fields
+
+	b, err := json.Marshal(schema)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestAvroDecoderOlderWriter(t *testing.T) {
+	writerSchema := withoutAvroFields(t, "correlation_id", "actor")
+	expected := eventtest.Event()
+	expected.CorrelationID, expected.Actor = "", ""
+
+	// Encoding the full event with the older schema drops the fields it lacks.
+	payload, err := avro.Marshal(avro.MustParse(writerSchema), map[string]any{
+		"id":          expected.ID,
+		"source":      expected.Source,
+		"type":        expected.Type.CloudEventType(),
+		"subject":     expected.Subject,
+		"time":        expected.Time,
+		"data_schema": expected.DataSchema,
+		"tenant_id":   expected.TenantID,
+		"product": map[string]any{
+			"id":          expected.Product.ID,
+			"name":        expected.Product.Name,
+			"description": expected.Product.Description,
+			"price":       int64(expected.Product.Price),
+			"created_at":  expected.Product.CreatedAt,
+		},
+	})
+	require.NoError(t, err)
+
+	decoder, err := productevent.NewAvroDecoder(writerSchema)
+	require.NoError(t, err)
+	event, err := decoder.Decode(payload)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, event)
+}
+
+func TestAvroDecoderIncompatibleWriter(t *testing.T) {
+	_, err := productevent.NewAvroDecoder(withoutAvroFields(t, "tenant_id"))
+
+	assert.Error(t, err, "A required field without default can't be read")
+}
+
+func TestDecodeProtobufUnknownFields(t *testing.T) {
+	message := protowire.AppendTag(append([]byte{}, eventtest.Protobuf...), 42, protowire.Fixed64Type)
+	message = protowire.AppendFixed64(message, 7)
+
+	event, err := productevent.DecodeProtobuf(message)
+
+	require.NoError(t, err)
+	assert.Equal(t, eventtest.Event(), event)
+}
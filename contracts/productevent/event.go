@@ -0,0 +1,132 @@
+// Package productevent is the contract of the product events products publishes
+// and notifications consumes: the event types, their constructor and validation,
+// and the JSON, Protobuf and Avro encodings.
+//
+// Changes must stay readable by consumers of the previous version. An
+// incompatible change of the event data needs a new SchemaVersion.
+package productevent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	Created Type = "product_created"
+	Deleted Type = "product_deleted"
+)
+
+// Valid reports whether t is a known event type.
+func (t Type) Valid() bool {
+	return t == Created || t == Deleted
+}
+
+// CloudEventType returns the CloudEvents type attribute for t, e.g. "products.product_created".
+func (t Type) CloudEventType() string {
+	return TypePrefix + string(t)
+}
+
+const (
+	// Source is the CloudEvents source of product events.
+	Source = "/products"
+	// TypePrefix namespaces product event types.
+	TypePrefix = "products."
+	// SchemaVersion is the version of the event data.
+	SchemaVersion = 1
+	// DataSchema identifies the shape and version of the event data.
+	DataSchema = "urn:products:schema:product-event:1"
+)
+
+var ErrInvalidEvent = errors.New("invalid product event")
+
+// Cents is an amount of money in cents.
+type Cents int64
+
+type Product struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Price       Cents     `json:"price"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Event is a product event with its CloudEvents attributes, independent of the
+// format it is encoded in. Events decoded from the legacy JSON format have no
+// ID, Source, Subject or DataSchema.
+type Event struct {
+	ID            string
+	Source        string
+	Type          Type
+	Subject       string
+	Time          time.Time
+	DataSchema    string
+	TenantID      string
+	CorrelationID string
+	Product       Product
+	// Actor is the authenticated subject that caused the event.
+	Actor string
+}
+
+// Metadata is the context an event is published in.
+type Metadata struct {
+	TenantID string
+	// CorrelationID is the id of the request that caused the event.
+	CorrelationID string
+	Actor         string
+}
+
+// New creates an event of type eventType about product.
+func New(eventType Type, product Product, meta Metadata) *Event {
+	return &Event{
+		ID:            uuid.NewString(),
+		Source:        Source,
+		Type:          eventType,
+		Subject:       product.ID,
+		Time:          time.Now().UTC(),
+		DataSchema:    DataSchema,
+		TenantID:      meta.TenantID,
+		CorrelationID: meta.CorrelationID,
+		Product:       product,
+		Actor:         meta.Actor,
+	}
+}
+
+// Validate checks the fields every consumer relies on.
+func (e *Event) Validate() error {
+	switch {
+	case !e.Type.Valid():
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, e.Type)
+	case e.Source != "" && e.ID == "":
+		return fmt.Errorf("%w: missing id", ErrInvalidEvent)
+	case e.Time.IsZero():
+		return fmt.Errorf("%w: missing time", ErrInvalidEvent)
+	case e.Product.ID == "":
+		return fmt.Errorf("%w: missing product id", ErrInvalidEvent)
+	case e.Product.Price < 0:
+		return fmt.Errorf("%w: negative price", ErrInvalidEvent)
+	}
+	return nil
+}
+
+// parseType maps a CloudEvents type attribute back to the event type.
+func parseType(cloudEventType string) (Type, error) {
+	eventType, ok := strings.CutPrefix(cloudEventType, TypePrefix)
+	if !ok {
+		return "", fmt.Errorf("%w: unexpected type %q", ErrInvalidEvent, cloudEventType)
+	}
+	return Type(eventType), nil
+}
+
+// validated returns decoded events that pass Validate.
+func validated(e *Event) (*Event, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
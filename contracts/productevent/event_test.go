@@ -0,0 +1,161 @@
+package productevent_test
+
+import (
+	"contracts/productevent"
+	"contracts/productevent/eventtest"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in eventtest/testdata")
+
+// TestGolden pins the encodings of the reference event. A failure means the
+// wire format changed, which consumers of the previous version may not read.
+func TestGolden(t *testing.T) {
+	type testCase struct {
+		file   string
+		golden []byte
+		encode func(*productevent.Event) ([]byte, error)
+	}
+
+	cases := []testCase{
+		{file: "event.json", golden: eventtest.JSON, encode: productevent.EncodeJSON},
+		{file: "event.pb", golden: eventtest.Protobuf, encode: productevent.EncodeProtobuf},
+		{file: "event.avro", golden: eventtest.Avro, encode: productevent.EncodeAvro},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.file, func(t *testing.T) {
+			encoded, err := tCase.encode(eventtest.Event())
+			require.NoError(t, err)
+
+			if *update {
+				require.NoError(t, os.WriteFile(filepath.Join("eventtest", "testdata", tCase.file), encoded, 0o644))
+				return
+			}
+			assert.Equal(t, tCase.golden, encoded)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	avroDecoder, err := productevent.NewAvroDecoder(productevent.AvroSchema)
+	require.NoError(t, err)
+
+	type testCase struct {
+		name    string
+		decode  func([]byte) (*productevent.Event, error)
+		message []byte
+	}
+
+	cases := []testCase{
+		{name: "JSON", decode: productevent.DecodeJSON, message: eventtest.JSON},
+		{name: "Protobuf", decode: productevent.DecodeProtobuf, message: eventtest.Protobuf},
+		{name: "Avro", decode: avroDecoder.Decode, message: eventtest.Avro},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			event, err := tCase.decode(tCase.message)
+
+			require.NoError(t, err)
+			assert.Equal(t, eventtest.Event(), event)
+		})
+	}
+}
+
+func TestDecodeLegacyJSON(t *testing.T) {
+	event, err := productevent.DecodeJSON(eventtest.LegacyJSON)
+
+	require.NoError(t, err)
+	expected := eventtest.Event()
+	expected.ID, expected.Source, expected.Subject, expected.DataSchema, expected.CorrelationID = "", "", "", "", ""
+	assert.Equal(t, expected, event)
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	cases := map[string]string{
+		"Unsupported Spec Version": `{"specversion": "0.3", "id": "1", "source": "/products", "type": "products.product_created"}`,
+		"Foreign Type":             `{"specversion": "1.0", "id": "1", "source": "/orders", "type": "orders.order_created"}`,
+		"Unknown Type":             `{"specversion": "1.0", "id": "1", "source": "/products", "type": "products.product_renamed", "time": "2024-01-01T12:00:00Z", "data": {"product": {"id": "1"}}}`,
+		"Missing Product":          `{"specversion": "1.0", "id": "1", "source": "/products", "type": "products.product_created", "time": "2024-01-01T12:00:00Z", "data": {}}`,
+		"Malformed":                `{"specversion": `,
+	}
+
+	for name, message := range cases {
+		t.Run(name, func(t *testing.T) {
+			event, err := productevent.DecodeJSON([]byte(message))
+
+			assert.Nil(t, event)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type testCase struct {
+		name   string
+		modify func(e *productevent.Event)
+	}
+
+	cases := []testCase{
+		{name: "Unknown Type", modify: func(e *productevent.Event) { e.Type = "product_renamed" }},
+		{name: "Missing ID", modify: func(e *productevent.Event) { e.ID = "" }},
+		{name: "Missing Time", modify: func(e *productevent.Event) { e.Time = time.Time{} }},
+		{name: "Missing Product ID", modify: func(e *productevent.Event) { e.Product.ID = "" }},
+		{name: "Negative Price", modify: func(e *productevent.Event) { e.Product.Price = -1 }},
+	}
+
+	require.NoError(t, eventtest.Event().Validate())
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			event := eventtest.Event()
+			tCase.modify(event)
+
+			assert.ErrorIs(t, event.Validate(), productevent.ErrInvalidEvent)
+			_, err := productevent.EncodeJSON(event)
+			assert.ErrorIs(t, err, productevent.ErrInvalidEvent, "Invalid events should not be encoded")
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	product := eventtest.Event().Product
+
+	event := productevent.New(productevent.Deleted, product, productevent.Metadata{TenantID: "brand-a", CorrelationID: "req-1", Actor: "user-42"})
+
+	require.NoError(t, event.Validate())
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, productevent.Source, event.Source)
+	assert.Equal(t, product.ID, event.Subject)
+	assert.Equal(t, productevent.DataSchema, event.DataSchema)
+	assert.Equal(t, "req-1", event.CorrelationID)
+	assert.Equal(t, product, event.Product)
+}
+
+func TestHeaders(t *testing.T) {
+	headers := productevent.Headers(eventtest.Event(), productevent.AvroContentType)
+
+	assert.Equal(t, map[string]string{
+		"event-type":     "products.product_created",
+		"content-type":   "application/avro",
+		"schema-version": "1",
+	}, headers)
+
+	eventType, ok := productevent.TypeFromHeaders(headers)
+	assert.True(t, ok)
+	assert.Equal(t, productevent.Created, eventType)
+
+	eventType, ok = productevent.TypeFromHeaders(map[string]string{"event-type": "orders.order_created"})
+	assert.True(t, ok)
+	assert.False(t, eventType.Valid())
+
+	_, ok = productevent.TypeFromHeaders(map[string]string{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,44 @@
+// Package eventtest holds a reference product event and its encodings. The
+// encodings are golden files of the productevent encoders, producers can check
+// their output against them and consumers that they decode them.
+package eventtest
+
+import (
+	"contracts/productevent"
+	_ "embed"
+	"time"
+)
+
+var (
+	//go:embed testdata/event.json
+	JSON []byte
+	//go:embed testdata/event.pb
+	Protobuf []byte
+	//go:embed testdata/event.avro
+	Avro []byte
+	// LegacyJSON is Event in the format used before CloudEvents, without the attributes it lacks.
+	//go:embed testdata/legacy.json
+	LegacyJSON []byte
+)
+
+// Event returns the event the golden files encode.
+func Event() *productevent.Event {
+	return &productevent.Event{
+		ID:            "3f0c6f5e-8f4a-4a43-9a47-4f3a8e0f7c11",
+		Source:        productevent.Source,
+		Type:          productevent.Created,
+		Subject:       "6a0e4f9d-1c64-4d8a-a3c2-6fd0c2d6a4b1",
+		Time:          time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		DataSchema:    productevent.DataSchema,
+		TenantID:      "brand-a",
+		CorrelationID: "3c9a0a8e-7d55-4a8e-9d0c-2b5a1f0e6c77",
+		Product: productevent.Product{
+			ID:          "6a0e4f9d-1c64-4d8a-a3c2-6fd0c2d6a4b1",
+			Name:        "Phone",
+			Description: "Smartphone",
+			Price:       50000,
+			CreatedAt:   time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+		},
+		Actor: "apikey:2b1d3c4e",
+	}
+}
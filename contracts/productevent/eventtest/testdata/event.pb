@@ -0,0 +1,4 @@
+
+$3f0c6f5e-8f4a-4a43-9a47-4f3a8e0f7c11	/productsproducts.product_created"$6a0e4f9d-1c64-4d8a-a3c2-6fd0c2d6a4b1(2#urn:products:schema:product-event:1:brand-aB$3c9a0a8e-7d55-4a8e-9d0c-2b5a1f0e6c77JF
+$6a0e4f9d-1c64-4d8a-a3c2-6fd0c2d6a4b1Phone
+Smartphone І(ÄRapikey:2b1d3c4e
\ No newline at end of file
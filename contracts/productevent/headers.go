@@ -0,0 +1,38 @@
+package productevent
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Message headers describing the event, so consumers can route and filter
+// messages before decoding them.
+const (
+	HeaderEventType     = "event-type"
+	HeaderContentType   = "content-type"
+	HeaderSchemaVersion = "schema-version"
+)
+
+// Headers returns the headers of e encoded with contentType.
+func Headers(e *Event, contentType string) map[string]string {
+	return map[string]string{
+		HeaderEventType:     e.Type.CloudEventType(),
+		HeaderContentType:   contentType,
+		HeaderSchemaVersion: strconv.Itoa(SchemaVersion),
+	}
+}
+
+// TypeFromHeaders returns the event type in headers, ok is false when there is
+// no event-type header. Types of other producers are returned as the empty,
+// invalid type.
+func TypeFromHeaders(headers map[string]string) (t Type, ok bool) {
+	value, ok := headers[HeaderEventType]
+	if !ok {
+		return "", false
+	}
+	eventType, found := strings.CutPrefix(value, TypePrefix)
+	if !found {
+		return "", true
+	}
+	return Type(eventType), true
+}
@@ -0,0 +1,124 @@
+package productevent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	// JSONContentType is the content type of structured mode CloudEvents.
+	JSONContentType = "application/cloudevents+json"
+)
+
+// CloudEvent is a CloudEvents 1.0 event in the structured JSON format,
+// see https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/formats/json-format.md.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype,omitempty"`
+	DataSchema      string    `json:"dataschema,omitempty"`
+	// Extension attributes.
+	TenantID string `json:"tenantid,omitempty"`
+	// CorrelationID is the id of the request that caused the event.
+	CorrelationID string          `json:"correlationid,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Data is the data of a product event in the JSON format.
+type Data struct {
+	Product Product `json:"product"`
+	Actor   string  `json:"actor,omitempty"`
+}
+
+// legacyEvent is the format products published before CloudEvents.
+type legacyEvent struct {
+	EventType Type      `json:"event_type"`
+	TenantID  string    `json:"tenant_id"`
+	Product   Product   `json:"product"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor,omitempty"`
+}
+
+// EncodeJSON encodes e as a structured mode CloudEvent.
+func EncodeJSON(e *Event) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(&Data{Product: e.Product, Actor: e.Actor})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              e.ID,
+		Source:          e.Source,
+		Type:            e.Type.CloudEventType(),
+		Subject:         e.Subject,
+		Time:            e.Time,
+		DataContentType: "application/json",
+		DataSchema:      e.DataSchema,
+		TenantID:        e.TenantID,
+		CorrelationID:   e.CorrelationID,
+		Data:            data,
+	})
+}
+
+// DecodeJSON decodes a structured mode CloudEvent, or an event in the legacy
+// format products used to publish before it.
+// TODO: drop the legacy format once no such events are left on the topic.
+func DecodeJSON(message []byte) (*Event, error) {
+	var envelope CloudEvent
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return nil, err
+	}
+
+	if envelope.SpecVersion == "" {
+		var legacy legacyEvent
+		if err := json.Unmarshal(message, &legacy); err != nil {
+			return nil, err
+		}
+		event := &Event{
+			Type:     legacy.EventType,
+			Time:     legacy.Timestamp,
+			TenantID: legacy.TenantID,
+			Product:  legacy.Product,
+			Actor:    legacy.Actor,
+		}
+		return validated(event)
+	}
+
+	if envelope.SpecVersion != CloudEventsSpecVersion {
+		return nil, fmt.Errorf("unsupported cloudevents specversion %q", envelope.SpecVersion)
+	}
+	eventType, err := parseType(envelope.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	var data Data
+	if err := json.Unmarshal(envelope.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+
+	event := &Event{
+		ID:            envelope.ID,
+		Source:        envelope.Source,
+		Type:          eventType,
+		Subject:       envelope.Subject,
+		Time:          envelope.Time,
+		DataSchema:    envelope.DataSchema,
+		TenantID:      envelope.TenantID,
+		CorrelationID: envelope.CorrelationID,
+		Product:       data.Product,
+		Actor:         data.Actor,
+	}
+	return validated(event)
+}
@@ -0,0 +1,169 @@
+package productevent
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ProtobufContentType is the content type of Protobuf encoded events.
+const ProtobufContentType = "application/x-protobuf"
+
+// The encoding of the products.events.v1.ProductEvent message from
+// schemas/product_event.proto is written by hand, keep it in sync with the schema.
+
+// EncodeProtobuf encodes e as a products.events.v1.ProductEvent message.
+func EncodeProtobuf(e *Event) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	var product []byte
+	product = appendString(product, 1, e.Product.ID)
+	product = appendString(product, 2, e.Product.Name)
+	product = appendString(product, 3, e.Product.Description)
+	product = appendInt64(product, 4, int64(e.Product.Price))
+	product = appendInt64(product, 5, unixMicros(e.Product.CreatedAt))
+
+	var b []byte
+	b = appendString(b, 1, e.ID)
+	b = appendString(b, 2, e.Source)
+	b = appendString(b, 3, e.Type.CloudEventType())
+	b = appendString(b, 4, e.Subject)
+	b = appendInt64(b, 5, unixMicros(e.Time))
+	b = appendString(b, 6, e.DataSchema)
+	b = appendString(b, 7, e.TenantID)
+	b = appendString(b, 8, e.CorrelationID)
+	b = protowire.AppendTag(b, 9, protowire.BytesType)
+	b = protowire.AppendBytes(b, product)
+	b = appendString(b, 10, e.Actor)
+
+	return b, nil
+}
+
+// DecodeProtobuf decodes a products.events.v1.ProductEvent message. Unknown
+// fields are skipped.
+func DecodeProtobuf(message []byte) (*Event, error) {
+	var (
+		event     Event
+		eventType string
+	)
+	err := consumeFields(message, func(num protowire.Number, value []byte) error {
+		switch num {
+		case 1:
+			event.ID = string(value)
+		case 2:
+			event.Source = string(value)
+		case 3:
+			eventType = string(value)
+		case 4:
+			event.Subject = string(value)
+		case 5:
+			event.Time = fromUnixMicros(varint(value))
+		case 6:
+			event.DataSchema = string(value)
+		case 7:
+			event.TenantID = string(value)
+		case 8:
+			event.CorrelationID = string(value)
+		case 9:
+			return consumeFields(value, func(num protowire.Number, value []byte) error {
+				switch num {
+				case 1:
+					event.Product.ID = string(value)
+				case 2:
+					event.Product.Name = string(value)
+				case 3:
+					event.Product.Description = string(value)
+				case 4:
+					event.Product.Price = Cents(varint(value))
+				case 5:
+					event.Product.CreatedAt = fromUnixMicros(varint(value))
+				}
+				return nil
+			})
+		case 10:
+			event.Actor = string(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode protobuf event: %w", err)
+	}
+
+	if event.Type, err = parseType(eventType); err != nil {
+		return nil, err
+	}
+	return validated(&event)
+}
+
+// appendString and appendInt64 skip zero values like proto3 does.
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendInt64(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// consumeFields calls fn with the field number and the raw value of each
+// varint and length delimited field in b. For varints value is the encoded varint.
+func consumeFields(b []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var value []byte
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			_, n = protowire.ConsumeVarint(b)
+			value = b[:max(n, 0)]
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if value != nil {
+			if err := fn(num, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func varint(b []byte) int64 {
+	v, _ := protowire.ConsumeVarint(b)
+	return int64(v)
+}
+
+func unixMicros(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMicro()
+}
+
+func fromUnixMicros(micros int64) time.Time {
+	if micros == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(micros).UTC()
+}
@@ -0,0 +1,12 @@
+package productevent
+
+import _ "embed"
+
+var (
+	// ProtobufSchema defines the products.events.v1.ProductEvent message.
+	//go:embed schemas/product_event.proto
+	ProtobufSchema string
+	// AvroSchema defines the products.events.v1.ProductEvent record.
+	//go:embed schemas/product_event.avsc
+	AvroSchema string
+)
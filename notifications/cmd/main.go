@@ -2,14 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"notifications/internal/config"
+	"notifications/internal/handlers"
 	loggerPkg "notifications/internal/logger"
 	"notifications/internal/messaging"
+	"notifications/internal/models"
+	"notifications/internal/notifier"
+	"notifications/internal/repository/sqlite"
+	"notifications/internal/serde"
 	"notifications/internal/service"
+	"notifications/internal/templates"
+	"notifications/internal/tracing"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -18,23 +29,105 @@ func main() {
 	logger := loggerPkg.NewLogger("development", zap.InfoLevel)
 	defer logger.Sync()
 
+	if len(os.Args) > 1 && os.Args[1] == "preview" {
+		os.Exit(runPreviewCommand(os.Args[2:]))
+	}
+
 	cfg := config.Load()
 
-	msgBroker, err := messaging.NewKafkaConsumer(messaging.Config{
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "notifications",
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.FilePath,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
+	msgBroker, err := messaging.NewBroker(messaging.Config{
+		Type:     cfg.MessageBroker.Type,
 		Endpoint: cfg.MessageBroker.Endpoint,
 		Topic:    cfg.MessageBroker.Topic,
 		GroupID:  cfg.MessageBroker.GroupID,
+
+		Stream:     cfg.MessageBroker.Stream,
+		MaxDeliver: cfg.MessageBroker.MaxDeliver,
+		RetryDelay: cfg.MessageBroker.RetryDelay,
 	}, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize message broker", zap.Error(err))
 	}
 
-	notificationService := service.NewNotificationService(logger)
-	consumer := messaging.NewConsumer(msgBroker, notificationService, logger)
+	deserializer, err := newEventDeserializer(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize event deserializer", zap.Error(err))
+	}
+
+	notifiers, err := newNotifiers(cfg.Notifiers)
+	if err != nil {
+		logger.Fatal("Failed to initialize notifiers", zap.Error(err))
+	}
+	if len(notifiers) == 0 {
+		logger.Warn("No notification channel is configured, events are only consumed")
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	var renderer service.MessageRenderer
+	if cfg.Notifiers.TemplatesDir != "" {
+		templateRenderer, err := templates.NewRenderer(cfg.Notifiers.TemplatesDir, logger)
+		if err != nil {
+			logger.Fatal("Failed to load notification templates", zap.Error(err))
+		}
+		go func() {
+			if err := templateRenderer.Watch(ctx); err != nil {
+				logger.Error("Failed to watch notification templates, changes need a restart", zap.Error(err))
+			}
+		}()
+		renderer = templateRenderer
+	}
+
+	db, err := sqlite.Open(ctx, cfg.Subscribers.DBPath)
+	if err != nil {
+		logger.Fatal("Failed to open subscribers database", zap.Error(err))
+	}
+	defer db.Close()
+
+	subscribersService, err := service.NewSubscribersService(ctx, sqlite.NewSubscribersRepository(db), newSubscriberNotifier(cfg.Notifiers), logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize subscribers", zap.Error(err))
+	}
+
+	notificationService := service.NewNotificationService(notifiers, subscribersService, renderer, service.RetryConfig{
+		Attempts: cfg.Notifiers.RetryAttempts,
+		Delay:    cfg.Notifiers.RetryDelay,
+	}, logger)
+	consumer := messaging.NewConsumer(msgBroker, deserializer, notificationService, logger)
+
+	var adminServer *http.Server
+	if cfg.Admin.Token != "" {
+		adminServer = &http.Server{
+			Addr:    fmt.Sprintf(":%s", cfg.Admin.Port),
+			Handler: handlers.SetupRoutes(handlers.NewSubscribersHandler(subscribersService, logger), cfg.Admin.Token, logger),
+		}
+		go func() {
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Fatal("Failed to run admin server", zap.Error(err))
+			}
+		}()
+	} else {
+		logger.Warn("ADMIN_TOKEN is not set, the admin API is disabled")
+	}
+
 	go func() {
 		if err := consumer.Start(ctx, runtime.NumCPU()); err != nil && err != context.Canceled {
 			logger.Fatal("Failed to start consumer", zap.Error(err))
@@ -45,7 +138,117 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 	logger.Info("Shutdown signal received")
+	if adminServer != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Admin server shutdown:", zap.Error(err))
+		}
+		cancelShutdown()
+	}
 	cancel()
 	consumer.Stop()
 	logger.Info("Shutdown complete")
 }
+
+// newEventDeserializer checks at startup that the events on the topic can be decoded.
+func newEventDeserializer(cfg *config.Config) (*serde.Deserializer, error) {
+	if cfg.Events.SchemaRegistryURL == "" {
+		return serde.NewDeserializer(nil), nil
+	}
+	deserializer := serde.NewDeserializer(serde.NewHTTPRegistry(cfg.Events.SchemaRegistryURL))
+
+	subject := cfg.Events.SchemaSubject
+	if subject == "" {
+		subject = cfg.MessageBroker.Topic + "-value"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := deserializer.CheckCompatibility(ctx, subject); err != nil {
+		return nil, err
+	}
+	return deserializer, nil
+}
+
+// newNotifiers creates the channels whose destination is configured.
+func newNotifiers(cfg config.NotifiersConfig) ([]notifier.Notifier, error) {
+	var notifiers []notifier.Notifier
+
+	if cfg.Email.Host != "" {
+		email, err := notifier.NewEmailNotifier(notifier.EmailConfig{
+			Host:     cfg.Email.Host,
+			Port:     cfg.Email.Port,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+			Timeout:  cfg.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, email)
+	}
+
+	if cfg.Chat.WebhookURL != "" {
+		chat, err := notifier.NewChatNotifier(notifier.ChatConfig{
+			WebhookURL: cfg.Chat.WebhookURL,
+			Format:     cfg.Chat.Format,
+			Timeout:    cfg.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, chat)
+	}
+
+	if cfg.HTTP.URL != "" {
+		http, err := notifier.NewHTTPNotifier(notifier.HTTPConfig{
+			URL:           cfg.HTTP.URL,
+			Authorization: cfg.HTTP.Authorization,
+			Timeout:       cfg.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, http)
+	}
+
+	return notifiers, nil
+}
+
+// newSubscriberNotifier creates the notifiers for subscriber addresses. Email
+// goes through the configured SMTP server, so it is only available with one.
+func newSubscriberNotifier(cfg config.NotifiersConfig) service.NotifierFactory {
+	return func(channel, address string) (notifier.Notifier, error) {
+		switch channel {
+		case models.ChannelEmail:
+			if cfg.Email.Host == "" {
+				return nil, errors.New("email is not available, NOTIFY_SMTP_HOST is not set")
+			}
+			return notifier.NewEmailNotifier(notifier.EmailConfig{
+				Host:     cfg.Email.Host,
+				Port:     cfg.Email.Port,
+				Username: cfg.Email.Username,
+				Password: cfg.Email.Password,
+				From:     cfg.Email.From,
+				To:       []string{address},
+				Timeout:  cfg.Timeout,
+			})
+		case models.ChannelSlack, models.ChannelTeams:
+			return notifier.NewChatNotifier(notifier.ChatConfig{
+				WebhookURL: address,
+				Format:     channel,
+				Timeout:    cfg.Timeout,
+			})
+		case models.ChannelHTTP:
+			return notifier.NewHTTPNotifier(notifier.HTTPConfig{
+				URL:     address,
+				Timeout: cfg.Timeout,
+			})
+		default:
+			return nil, fmt.Errorf("unknown channel %q, expected %s, %s, %s or %s", channel, models.ChannelEmail, models.ChannelSlack, models.ChannelTeams, models.ChannelHTTP)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"contracts/productevent"
+	"flag"
+	"fmt"
+	"io"
+	"notifications/internal/templates"
+	"os"
+)
+
+const previewUsage = `Usage:
+  main preview -templates <dir> -channel <name> [-event <file.json>|-] [-type product_created]
+
+Renders the templates for a channel against a JSON CloudEvent, "-" reads it
+from stdin. Without -event a sample event of -type is used.
+`
+
+// runPreviewCommand renders a notification from the templates and returns the process exit code.
+func runPreviewCommand(args []string) int {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, previewUsage) }
+	dir := fs.String("templates", os.Getenv("NOTIFY_TEMPLATES_DIR"), "templates directory")
+	channel := fs.String("channel", templates.DefaultChannel, "channel to render for: email, slack, teams, http or default")
+	eventFile := fs.String("event", "", "JSON CloudEvent file, - for stdin")
+	eventType := fs.String("type", string(productevent.Created), "event type of the sample event")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *dir == "" {
+		fmt.Fprint(os.Stderr, previewUsage)
+		return 2
+	}
+
+	if err := preview(os.Stdout, *dir, *channel, *eventFile, productevent.Type(*eventType)); err != nil {
+		fmt.Fprintf(os.Stderr, "preview: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func preview(w io.Writer, dir, channel, eventFile string, eventType productevent.Type) error {
+	set, err := templates.Load(dir)
+	if err != nil {
+		return err
+	}
+
+	event, err := previewEvent(eventFile, eventType)
+	if err != nil {
+		return err
+	}
+
+	msg, ok, err := set.Render(event, channel)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no %s templates for channel %s or %s", event.Type, channel, templates.DefaultChannel)
+	}
+
+	fmt.Fprintf(w, "Subject: %s\n\n--- text ---\n%s\n", msg.Subject, msg.Text)
+	if msg.HTML != "" {
+		fmt.Fprintf(w, "\n--- html ---\n%s\n", msg.HTML)
+	}
+	return nil
+}
+
+func previewEvent(eventFile string, eventType productevent.Type) (*productevent.Event, error) {
+	if eventFile == "" {
+		if !eventType.Valid() {
+			return nil, fmt.Errorf("unknown event type %q", eventType)
+		}
+		return templates.SampleEvent(eventType), nil
+	}
+
+	var data []byte
+	var err error
+	if eventFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(eventFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return productevent.DecodeJSON(data)
+}
@@ -0,0 +1,8 @@
+package apperrors
+
+import "errors"
+
+var (
+	ErrSubscriberNotFound = errors.New("subscriber not found")
+	ErrSubscriberInvalid  = errors.New("invalid subscriber")
+)
@@ -2,18 +2,95 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	MessageBroker MessageBrokerConfig
+	Tracing       TracingConfig
+	Events        EventsConfig
+	Notifiers     NotifiersConfig
+	Subscribers   SubscribersConfig
+	Admin         AdminConfig
 }
 
 type MessageBrokerConfig struct {
+	// Type is "kafka", "memory" or "nats".
+	Type     string
 	Endpoint string
 	Topic    string
 	GroupID  string
+
+	// Stream, MaxDeliver and RetryDelay configure the NATS JetStream consumer.
+	Stream     string
+	MaxDeliver int
+	RetryDelay time.Duration
+}
+
+type TracingConfig struct {
+	// Exporter is one of none, stdout, file or otlp.
+	Exporter    string
+	FilePath    string
+	SampleRatio float64
+}
+
+type EventsConfig struct {
+	// SchemaRegistryURL is needed to decode Protobuf and Avro events.
+	SchemaRegistryURL string
+	// SchemaSubject defaults to "<topic>-value".
+	SchemaSubject string
+}
+
+// NotifiersConfig enables a channel when its destination is set.
+type NotifiersConfig struct {
+	Email EmailConfig
+	Chat  ChatConfig
+	HTTP  HTTPNotifierConfig
+	// Timeout bounds sending a single notification.
+	Timeout time.Duration
+	// RetryAttempts and RetryDelay control how a failed notification is retried.
+	RetryAttempts int
+	RetryDelay    time.Duration
+	// TemplatesDir holds the message templates, empty uses the built-in messages.
+	TemplatesDir string
+}
+
+type EmailConfig struct {
+	// Host enables email notifications.
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+type ChatConfig struct {
+	// WebhookURL enables chat notifications.
+	WebhookURL string
+	// Format is slack or teams.
+	Format string
+}
+
+type HTTPNotifierConfig struct {
+	// URL enables HTTP notifications.
+	URL           string
+	Authorization string
+}
+
+type SubscribersConfig struct {
+	// DBPath is the SQLite database the subscribers are stored in.
+	DBPath string
+}
+
+type AdminConfig struct {
+	Port string
+	// Token enables the admin API, requests have to send it as a bearer token.
+	Token string
 }
 
 func Load() *Config {
@@ -22,9 +99,52 @@ func Load() *Config {
 
 	return &Config{
 		MessageBroker: MessageBrokerConfig{
+			Type:     getEnv("MESSAGE_BROKER_TYPE", "kafka"),
 			Endpoint: getEnv("MESSAGE_BROKER_ENDPOINT", "localhost:9094"),
 			Topic:    getEnv("MESSAGE_BROKER_TOPIC", "product-events"),
 			GroupID:  getEnv("CONSUMER_GROUP_ID", "notifications-group"),
+
+			Stream:     getEnv("MESSAGE_BROKER_STREAM", "PRODUCT_EVENTS"),
+			MaxDeliver: getEnvInt("MESSAGE_BROKER_MAX_DELIVER", 5),
+			RetryDelay: getEnvDuration("MESSAGE_BROKER_RETRY_DELAY", 5*time.Second),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnv("TRACING_EXPORTER", "none"),
+			FilePath:    getEnv("TRACING_FILE_PATH", "traces.jsonl"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		Events: EventsConfig{
+			SchemaRegistryURL: getEnv("SCHEMA_REGISTRY_URL", ""),
+			SchemaSubject:     getEnv("EVENTS_SCHEMA_SUBJECT", ""),
+		},
+		Notifiers: NotifiersConfig{
+			Email: EmailConfig{
+				Host:     getEnv("NOTIFY_SMTP_HOST", ""),
+				Port:     getEnv("NOTIFY_SMTP_PORT", "587"),
+				Username: getEnv("NOTIFY_SMTP_USERNAME", ""),
+				Password: getEnv("NOTIFY_SMTP_PASSWORD", ""),
+				From:     getEnv("NOTIFY_SMTP_FROM", ""),
+				To:       getEnvList("NOTIFY_SMTP_TO"),
+			},
+			Chat: ChatConfig{
+				WebhookURL: getEnv("NOTIFY_CHAT_WEBHOOK_URL", ""),
+				Format:     getEnv("NOTIFY_CHAT_FORMAT", "slack"),
+			},
+			HTTP: HTTPNotifierConfig{
+				URL:           getEnv("NOTIFY_HTTP_URL", ""),
+				Authorization: getEnv("NOTIFY_HTTP_AUTHORIZATION", ""),
+			},
+			Timeout:       getEnvDuration("NOTIFY_TIMEOUT", 10*time.Second),
+			RetryAttempts: getEnvInt("NOTIFY_RETRY_ATTEMPTS", 3),
+			RetryDelay:    getEnvDuration("NOTIFY_RETRY_DELAY", time.Second),
+			TemplatesDir:  getEnv("NOTIFY_TEMPLATES_DIR", ""),
+		},
+		Subscribers: SubscribersConfig{
+			DBPath: getEnv("SUBSCRIBERS_DB_PATH", "notifications.db"),
+		},
+		Admin: AdminConfig{
+			Port:  getEnv("ADMIN_HTTP_PORT", "8082"),
+			Token: getEnv("ADMIN_TOKEN", ""),
 		},
 	}
 }
@@ -35,3 +155,41 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList splits a comma separated value, dropping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for value := range strings.SplitSeq(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
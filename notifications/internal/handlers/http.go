@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SetupRoutes creates the admin API. Every route requires adminToken as a
+// bearer token.
+func SetupRoutes(subscribersHandler *SubscribersHandler, adminToken string, logger *zap.Logger) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(requireToken(adminToken, logger))
+
+	router.POST("/subscribers", subscribersHandler.Create)
+	router.GET("/subscribers", subscribersHandler.List)
+	router.GET("/subscribers/:id", subscribersHandler.Get)
+	router.PATCH("/subscribers/:id", subscribersHandler.Update)
+	router.DELETE("/subscribers/:id", subscribersHandler.Delete)
+
+	return router
+}
+
+func requireToken(token string, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("AdminAuth")
+	return func(c *gin.Context) {
+		presented, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			logger.Warn("Rejected admin request", zap.String("path", c.Request.URL.Path), zap.String("client_ip", c.ClientIP()))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Unauthorized",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"notifications/internal/apperrors"
+	loggerPkg "notifications/internal/logger"
+	"notifications/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SubscribersService interface {
+	Create(ctx context.Context, createDTO *models.CreateSubscriberDTO) (*models.Subscriber, error)
+	Get(ctx context.Context, id string) (*models.Subscriber, error)
+	List(ctx context.Context) ([]models.Subscriber, error)
+	Update(ctx context.Context, id string, updateDTO *models.UpdateSubscriberDTO) (*models.Subscriber, error)
+	Delete(ctx context.Context, id string) (*models.Subscriber, error)
+}
+
+type SubscribersHandler struct {
+	sService SubscribersService
+	logger   *zap.Logger
+}
+
+func NewSubscribersHandler(sService SubscribersService, logger *zap.Logger) *SubscribersHandler {
+	return &SubscribersHandler{
+		sService: sService,
+		logger:   logger.Named("SubscribersHandler"),
+	}
+}
+
+func (h *SubscribersHandler) Create(c *gin.Context) {
+	var createDTO models.CreateSubscriberDTO
+	if err := c.ShouldBindJSON(&createDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, err := h.sService.Create(c.Request.Context(), &createDTO)
+	if err != nil {
+		h.error(c, "Error creating subscriber:", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+func (h *SubscribersHandler) List(c *gin.Context) {
+	subs, err := h.sService.List(c.Request.Context())
+	if err != nil {
+		h.error(c, "Error listing subscribers:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    subs,
+	})
+}
+
+func (h *SubscribersHandler) Get(c *gin.Context) {
+	var idDTO models.SubscriberIDDTO
+	if err := c.ShouldBindUri(&idDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, err := h.sService.Get(c.Request.Context(), idDTO.ID)
+	if err != nil {
+		h.error(c, "Error getting subscriber:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+func (h *SubscribersHandler) Update(c *gin.Context) {
+	var idDTO models.SubscriberIDDTO
+	if err := c.ShouldBindUri(&idDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+	var updateDTO models.UpdateSubscriberDTO
+	if err := c.ShouldBindJSON(&updateDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, err := h.sService.Update(c.Request.Context(), idDTO.ID, &updateDTO)
+	if err != nil {
+		h.error(c, "Error updating subscriber:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+func (h *SubscribersHandler) Delete(c *gin.Context) {
+	var idDTO models.SubscriberIDDTO
+	if err := c.ShouldBindUri(&idDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, err := h.sService.Delete(c.Request.Context(), idDTO.ID)
+	if err != nil {
+		h.error(c, "Error deleting subscriber:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+func (h *SubscribersHandler) badRequest(c *gin.Context, err error) {
+	loggerPkg.WithContext(c.Request.Context(), h.logger).Error("Subscriber request binding error:", zap.Error(err))
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+func (h *SubscribersHandler) error(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrSubscriberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, apperrors.ErrSubscriberInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		loggerPkg.WithContext(c.Request.Context(), h.logger).Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Internal Server Error",
+		})
+	}
+}
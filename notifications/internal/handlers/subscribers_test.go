@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"notifications/internal/apperrors"
+	"notifications/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testAdminToken   = "admin-token"
+	testSubscriberID = "8f293f9f-9bd0-4294-bd17-4fb80aa2650a"
+)
+
+type MockSubscribersService struct {
+	mock.Mock
+}
+
+func (m *MockSubscribersService) Create(ctx context.Context, createDTO *models.CreateSubscriberDTO) (*models.Subscriber, error) {
+	args := m.Called(ctx, createDTO)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscribersService) Get(ctx context.Context, id string) (*models.Subscriber, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscribersService) List(ctx context.Context) ([]models.Subscriber, error) {
+	args := m.Called(ctx)
+	subs, _ := args.Get(0).([]models.Subscriber)
+	return subs, args.Error(1)
+}
+
+func (m *MockSubscribersService) Update(ctx context.Context, id string, updateDTO *models.UpdateSubscriberDTO) (*models.Subscriber, error) {
+	args := m.Called(ctx, id, updateDTO)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscribersService) Delete(ctx context.Context, id string) (*models.Subscriber, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func setupAdminRouter() (*MockSubscribersService, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockSubscribersService{}
+	return mockService, SetupRoutes(NewSubscribersHandler(mockService, zap.NewNop()), testAdminToken, zap.NewNop())
+}
+
+func adminRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAuthentication(t *testing.T) {
+	type testCase struct {
+		name          string
+		authorization string
+	}
+
+	cases := []testCase{
+		{name: "Missing Token", authorization: ""},
+		{name: "Wrong Token", authorization: "Bearer other-token"},
+		{name: "Wrong Scheme", authorization: "Basic " + testAdminToken},
+	}
+
+	mockService, router := setupAdminRouter()
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/subscribers", nil)
+			if tCase.authorization != "" {
+				req.Header.Set("Authorization", tCase.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+	mockService.AssertNotCalled(t, "List", mock.Anything)
+}
+
+func TestSubscribersHandler_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService, router := setupAdminRouter()
+		sub := &models.Subscriber{
+			ID:        testSubscriberID,
+			Name:      "Sales",
+			Rule:      `product.price > 10000`,
+			Addresses: []models.SubscriberAddress{{Channel: models.ChannelEmail, Address: "sales@example.com"}},
+			Active:    true,
+		}
+		mockService.On("Create", mock.Anything, &models.CreateSubscriberDTO{
+			Name:      sub.Name,
+			Rule:      sub.Rule,
+			Addresses: sub.Addresses,
+		}).Return(sub, nil).Once()
+
+		w := adminRequest(router, http.MethodPost, "/subscribers", `{"name":"Sales","rule":"product.price > 10000","addresses":[{"channel":"email","address":"sales@example.com"}]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp struct {
+			Success bool               `json:"success"`
+			Data    *models.Subscriber `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Success)
+		assert.Equal(t, sub, resp.Data)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request", func(t *testing.T) {
+		type testCase struct {
+			name string
+			body string
+		}
+
+		cases := []testCase{
+			{name: "Missing Name", body: `{"addresses":[{"channel":"email","address":"sales@example.com"}]}`},
+			{name: "No Addresses", body: `{"name":"Sales","addresses":[]}`},
+			{name: "Address Without Channel", body: `{"name":"Sales","addresses":[{"address":"sales@example.com"}]}`},
+			{name: "Invalid JSON", body: `{"name":`},
+		}
+
+		mockService, router := setupAdminRouter()
+
+		for _, tCase := range cases {
+			t.Run(tCase.name, func(t *testing.T) {
+				w := adminRequest(router, http.MethodPost, "/subscribers", tCase.body)
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		}
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Rule", func(t *testing.T) {
+		mockService, router := setupAdminRouter()
+		mockService.On("Create", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: rule: unknown name color", apperrors.ErrSubscriberInvalid)).Once()
+
+		w := adminRequest(router, http.MethodPost, "/subscribers", `{"name":"Sales","rule":"product.color == 1","addresses":[{"channel":"email","address":"sales@example.com"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unknown name color")
+	})
+}
+
+func TestSubscribersHandler_Errors(t *testing.T) {
+	type testCase struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		setup          func(m *MockSubscribersService)
+		expectedStatus int
+	}
+
+	cases := []testCase{
+		{
+			name:   "Get Not Found",
+			method: http.MethodGet,
+			path:   "/subscribers/" + testSubscriberID,
+			setup: func(m *MockSubscribersService) {
+				m.On("Get", mock.Anything, testSubscriberID).Return(nil, apperrors.ErrSubscriberNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Get Invalid ID",
+			method:         http.MethodGet,
+			path:           "/subscribers/not-a-uuid",
+			setup:          func(m *MockSubscribersService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Update Not Found",
+			method: http.MethodPatch,
+			path:   "/subscribers/" + testSubscriberID,
+			body:   `{"active":false}`,
+			setup: func(m *MockSubscribersService) {
+				m.On("Update", mock.Anything, testSubscriberID, mock.Anything).Return(nil, apperrors.ErrSubscriberNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Update Empty Addresses",
+			method:         http.MethodPatch,
+			path:           "/subscribers/" + testSubscriberID,
+			body:           `{"addresses":[]}`,
+			setup:          func(m *MockSubscribersService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Delete Internal Error",
+			method: http.MethodDelete,
+			path:   "/subscribers/" + testSubscriberID,
+			setup: func(m *MockSubscribersService) {
+				m.On("Delete", mock.Anything, testSubscriberID).Return(nil, fmt.Errorf("database is locked")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			mockService, router := setupAdminRouter()
+			tCase.setup(mockService)
+
+			w := adminRequest(router, tCase.method, tCase.path, tCase.body)
+
+			assert.Equal(t, tCase.expectedStatus, w.Code)
+			assert.NotContains(t, w.Body.String(), "database is locked")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
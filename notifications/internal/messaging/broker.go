@@ -0,0 +1,104 @@
+package messaging
+
+import (
+	"context"
+	"contracts/membroker"
+	"fmt"
+	loggerPkg "notifications/internal/logger"
+	"notifications/internal/requestid"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("notifications/internal/messaging")
+
+const (
+	// TypeKafka consumes from Kafka, it needs a cgo build with librdkafka.
+	TypeKafka = "kafka"
+	// TypeMemory consumes from an in-process broker, for local development and tests.
+	TypeMemory = "memory"
+	// TypeNATS consumes from a NATS JetStream stream with a durable consumer.
+	TypeNATS = "nats"
+)
+
+// ProducerHeader names the service that produced a message.
+const ProducerHeader = "producer"
+
+// KeyHeader carries the message key on brokers without native keys, such as NATS.
+const KeyHeader = "message-key"
+
+type Config struct {
+	// Type is TypeKafka (the default), TypeMemory or TypeNATS.
+	Type     string
+	Endpoint string
+	Topic    string
+	GroupID  string
+
+	// Stream is the JetStream stream capturing Topic, only used by TypeNATS.
+	Stream string
+	// MaxDeliver limits how often a failed message is delivered before it is
+	// dropped, only used by TypeNATS. 0 uses the default.
+	MaxDeliver int
+	// RetryDelay is how long a failed message waits before it is redelivered,
+	// only used by TypeNATS.
+	RetryDelay time.Duration
+}
+
+// NewBroker creates the broker of type cfg.Type.
+func NewBroker(cfg Config, logger *zap.Logger) (MessageBroker, error) {
+	switch cfg.Type {
+	case "", TypeKafka:
+		return newKafkaConsumer(cfg, logger)
+	case TypeMemory:
+		logger.Named("MessageBroker").Warn("Using the in-memory message broker, only producers in this process are consumed")
+		return NewMemoryConsumer(membroker.New(membroker.DefaultPartitions), cfg, logger), nil
+	case TypeNATS:
+		consumer, err := NewNATSConsumer(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return consumer, nil
+	default:
+		return nil, fmt.Errorf("unknown message broker type %q", cfg.Type)
+	}
+}
+
+// process runs the handler for one message inside a consumer span that continues
+// the producer's trace, with the producer's request ID in the context. The
+// handler's error is logged and returned.
+func process(ctx context.Context, msg *Message, handler func(ctx context.Context, msg *Message) error, logger *zap.Logger, attrs ...attribute.KeyValue) error {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Headers))
+	if id := msg.Headers[requestid.MessageHeader]; id != "" {
+		ctx = requestid.NewContext(ctx, id)
+	}
+
+	ctx, span := tracer.Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+		),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	err := handler(ctx, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		loggerPkg.WithContext(ctx, logger).Error("Failed to process message",
+			zap.Error(err),
+			zap.String("message", string(msg.Value)),
+		)
+	}
+	return err
+}
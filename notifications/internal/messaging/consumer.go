@@ -2,43 +2,68 @@ package messaging
 
 import (
 	"context"
-	"encoding/json"
-	"notifications/internal/models"
+	"contracts/productevent"
+	loggerPkg "notifications/internal/logger"
 	"notifications/internal/service"
 
 	"go.uber.org/zap"
 )
 
+// Message is a consumed message. Headers holds the last value of every header key.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+}
+
 type MessageBroker interface {
-	Consume(ctx context.Context, workerCount int, handler func(message []byte) error) error
+	Consume(ctx context.Context, workerCount int, handler func(ctx context.Context, msg *Message) error) error
 	Close() error
 }
 
+type EventDeserializer interface {
+	Deserialize(ctx context.Context, message []byte) (*productevent.Event, error)
+}
+
 type Consumer struct {
-	broker  MessageBroker
-	service service.NotificationService
-	logger  *zap.Logger
+	broker       MessageBroker
+	deserializer EventDeserializer
+	service      service.NotificationService
+	logger       *zap.Logger
 }
 
-func NewConsumer(broker MessageBroker, service service.NotificationService, logger *zap.Logger) *Consumer {
+func NewConsumer(broker MessageBroker, deserializer EventDeserializer, service service.NotificationService, logger *zap.Logger) *Consumer {
 	return &Consumer{
-		broker:  broker,
-		service: service,
-		logger:  logger.Named("Consumer"),
+		broker:       broker,
+		deserializer: deserializer,
+		service:      service,
+		logger:       logger.Named("Consumer"),
 	}
 }
 
 func (c *Consumer) Start(ctx context.Context, workerCount int) error {
-	return c.broker.Consume(ctx, workerCount, func(message []byte) error {
-		var event models.ProductEvent
-		if err := json.Unmarshal(message, &event); err != nil {
-			c.logger.Error("Failed to unmarshal event",
+	return c.broker.Consume(ctx, workerCount, func(ctx context.Context, msg *Message) error {
+		// Messages from producers that set headers are filtered before decoding.
+		if eventType, ok := productevent.TypeFromHeaders(msg.Headers); ok && !eventType.Valid() {
+			loggerPkg.WithContext(ctx, c.logger).Debug("Skipping unknown event type",
+				zap.String("event_type", msg.Headers[productevent.HeaderEventType]),
+				zap.String("producer", msg.Headers[ProducerHeader]),
+			)
+			return nil
+		}
+
+		event, err := c.deserializer.Deserialize(ctx, msg.Value)
+		if err != nil {
+			loggerPkg.WithContext(ctx, c.logger).Error("Failed to unmarshal event",
 				zap.Error(err),
 			)
 			return err
 		}
 
-		return c.service.HandleProductEvent(ctx, &event)
+		return c.service.HandleProductEvent(ctx, event)
 	})
 }
 
@@ -0,0 +1,103 @@
+package messaging
+
+import (
+	"context"
+	"contracts/membroker"
+	"contracts/productevent"
+	"contracts/productevent/eventtest"
+	"notifications/internal/requestid"
+	"notifications/internal/serde"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeBroker hands its messages to the handler once.
+type fakeBroker struct {
+	messages []*Message
+	errs     []error
+}
+
+func (b *fakeBroker) Consume(ctx context.Context, workerCount int, handler func(ctx context.Context, msg *Message) error) error {
+	for _, msg := range b.messages {
+		b.errs = append(b.errs, handler(ctx, msg))
+	}
+	return nil
+}
+
+func (b *fakeBroker) Close() error {
+	return nil
+}
+
+type recordingService struct {
+	mu         sync.Mutex
+	events     []*productevent.Event
+	requestIDs []string
+}
+
+func (s *recordingService) HandleProductEvent(ctx context.Context, event *productevent.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := requestid.FromContext(ctx)
+	s.events = append(s.events, event)
+	s.requestIDs = append(s.requestIDs, id)
+	return nil
+}
+
+func TestConsumerFiltersByEventTypeHeader(t *testing.T) {
+	broker := &fakeBroker{messages: []*Message{
+		{Value: eventtest.JSON, Headers: map[string]string{"event-type": "products.product_created"}},
+		{Value: eventtest.JSON},
+		// Skipped without decoding, the value isn't even a product event.
+		{Value: []byte("not an event"), Headers: map[string]string{"event-type": "orders.order_created"}},
+		{Value: []byte("not an event"), Headers: map[string]string{"event-type": "products.product_renamed"}},
+		{Value: []byte("not an event")},
+	}}
+	service := &recordingService{}
+	consumer := NewConsumer(broker, serde.NewDeserializer(nil), service, zap.NewNop())
+
+	require.NoError(t, consumer.Start(context.Background(), 1))
+
+	assert.Len(t, service.events, 2)
+	assert.NoError(t, broker.errs[0])
+	assert.NoError(t, broker.errs[2])
+	assert.NoError(t, broker.errs[3])
+	assert.Error(t, broker.errs[4], "Messages without headers are still decoded")
+}
+
+func TestConsumerWithMemoryBroker(t *testing.T) {
+	broker := membroker.New(2)
+	defer broker.Close()
+	service := &recordingService{}
+	consumer := NewConsumer(NewMemoryConsumer(broker, Config{Topic: "product-events", GroupID: "notifications"}, zap.NewNop()), serde.NewDeserializer(nil), service, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Start(ctx, 2)
+	}()
+
+	headers := productevent.Headers(eventtest.Event(), productevent.JSONContentType)
+	headers["request-id"] = "req-1"
+	require.NoError(t, broker.Send(ctx, "product-events", eventtest.JSON, []byte(eventtest.Event().Product.ID), headers))
+
+	require.Eventually(t, func() bool {
+		service.mu.Lock()
+		defer service.mu.Unlock()
+		return len(service.events) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	assert.Equal(t, eventtest.Event(), service.events[0])
+	assert.Equal(t, []string{"req-1"}, service.requestIDs, "The request id header should be in the context")
+	var committed int64
+	for _, offset := range broker.Offsets("product-events", "notifications") {
+		committed += offset
+	}
+	assert.Equal(t, int64(1), committed, "The offset of the handled message should be committed")
+}
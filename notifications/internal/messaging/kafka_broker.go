@@ -1,3 +1,5 @@
+//go:build cgo
+
 package messaging
 
 import (
@@ -6,16 +8,11 @@ import (
 	"sync"
 	"time"
 
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.uber.org/zap"
 	"gopkg.in/confluentinc/confluent-kafka-go.v1/kafka"
 )
 
-type Config struct {
-	Endpoint string
-	Topic    string
-	GroupID  string
-}
-
 type KafkaConsumer struct {
 	consumer *kafka.Consumer
 	topic    string
@@ -48,7 +45,7 @@ func NewKafkaConsumer(cfg Config, logger *zap.Logger) (MessageBroker, error) {
 	return k, nil
 }
 
-func (k *KafkaConsumer) Consume(ctx context.Context, workerCount int, handler func(message []byte) error) error {
+func (k *KafkaConsumer) Consume(ctx context.Context, workerCount int, handler func(ctx context.Context, msg *Message) error) error {
 	tasks := make(chan *kafka.Message)
 
 	for range workerCount {
@@ -56,13 +53,7 @@ func (k *KafkaConsumer) Consume(ctx context.Context, workerCount int, handler fu
 		go func() {
 			defer k.wg.Done()
 			for msg := range tasks {
-				err := handler(msg.Value)
-				if err != nil {
-					k.logger.Error("Failed to process message",
-						zap.Error(err),
-						zap.String("message", string(msg.Value)),
-					)
-				}
+				k.process(ctx, msg, handler)
 			}
 		}()
 	}
@@ -111,6 +102,33 @@ func (k *KafkaConsumer) Consume(ctx context.Context, workerCount int, handler fu
 	}
 }
 
+func (k *KafkaConsumer) process(ctx context.Context, msg *kafka.Message, handler func(ctx context.Context, msg *Message) error) {
+	_ = process(ctx, &Message{
+		Topic:     k.topic,
+		Partition: int(msg.TopicPartition.Partition),
+		Offset:    int64(msg.TopicPartition.Offset),
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headerMap(msg.Headers),
+	}, handler, k.logger,
+		semconv.MessagingSystemKafka,
+		semconv.MessagingConsumerGroupName(k.groupID),
+		semconv.MessagingKafkaOffset(int(msg.TopicPartition.Offset)),
+	)
+}
+
+func headerMap(headers []kafka.Header) map[string]string {
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		m[h.Key] = string(h.Value)
+	}
+	return m
+}
+
+func newKafkaConsumer(cfg Config, logger *zap.Logger) (MessageBroker, error) {
+	return NewKafkaConsumer(cfg, logger)
+}
+
 func (k *KafkaConsumer) Close() error {
 	k.wg.Wait()
 	if k.consumer != nil {
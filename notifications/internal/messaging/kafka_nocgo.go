@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package messaging
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+func newKafkaConsumer(cfg Config, logger *zap.Logger) (MessageBroker, error) {
+	return nil, errors.New("kafka support needs a cgo build, set CGO_ENABLED=1 or use the memory message broker")
+}
@@ -0,0 +1,51 @@
+package messaging
+
+import (
+	"context"
+	"contracts/membroker"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.uber.org/zap"
+)
+
+// MemoryConsumer consumes from an in-process broker as a member of a consumer group.
+type MemoryConsumer struct {
+	broker  *membroker.Broker
+	topic   string
+	groupID string
+	logger  *zap.Logger
+}
+
+func NewMemoryConsumer(broker *membroker.Broker, cfg Config, logger *zap.Logger) *MemoryConsumer {
+	return &MemoryConsumer{
+		broker:  broker,
+		topic:   cfg.Topic,
+		groupID: cfg.GroupID,
+		logger:  logger.Named("MemoryConsumer"),
+	}
+}
+
+func (c *MemoryConsumer) Consume(ctx context.Context, workerCount int, handler func(ctx context.Context, msg *Message) error) error {
+	c.logger.Info("Started consuming messages from topic", zap.String("topic", c.topic))
+
+	return c.broker.Consume(ctx, c.topic, c.groupID, workerCount, func(ctx context.Context, msg *membroker.Message) error {
+		// Failed messages are logged and committed, like with Kafka.
+		_ = process(ctx, &Message{
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Headers:   msg.Headers,
+		}, handler, c.logger,
+			semconv.MessagingSystemKey.String(TypeMemory),
+			semconv.MessagingConsumerGroupName(c.groupID),
+		)
+		return nil
+	})
+}
+
+// Close leaves the broker running, it may be shared with other consumers and producers.
+func (c *MemoryConsumer) Close() error {
+	return nil
+}
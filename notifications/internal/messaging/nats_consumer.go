@@ -0,0 +1,245 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxDeliver = 5
+	defaultRetryDelay = 5 * time.Second
+	ackWait           = 30 * time.Second
+	// Repeated errors fetching messages are retried after a delay doubling from
+	// minErrorBackoff up to maxErrorBackoff.
+	minErrorBackoff = 100 * time.Millisecond
+	maxErrorBackoff = 5 * time.Second
+)
+
+// NATSConsumer consumes from a JetStream stream with a durable consumer named
+// after the group ID, so consumers sharing a group ID share the messages and a
+// restarted consumer resumes where the group left off.
+//
+// A message is acked when the handler succeeds and nacked when it fails, which
+// redelivers it after RetryDelay until it has been delivered MaxDeliver times.
+// Messages the handler doesn't finish within the ack wait are redelivered too.
+type NATSConsumer struct {
+	conn       *nats.Conn
+	js         jetstream.JetStream
+	stream     string
+	topic      string
+	groupID    string
+	maxDeliver int
+	retryDelay time.Duration
+
+	logger *zap.Logger
+	wg     sync.WaitGroup
+}
+
+func NewNATSConsumer(cfg Config, logger *zap.Logger) (*NATSConsumer, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("nats endpoint is required")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("nats topic is required")
+	}
+	if cfg.Stream == "" {
+		return nil, fmt.Errorf("nats stream is required")
+	}
+	if cfg.GroupID == "" {
+		return nil, fmt.Errorf("nats consumer group id is required")
+	}
+	if cfg.MaxDeliver <= 0 {
+		cfg.MaxDeliver = defaultMaxDeliver
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+
+	conn, err := nats.Connect(cfg.Endpoint, nats.Name(cfg.GroupID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	return &NATSConsumer{
+		conn:       conn,
+		js:         js,
+		stream:     cfg.Stream,
+		topic:      cfg.Topic,
+		groupID:    cfg.GroupID,
+		maxDeliver: cfg.MaxDeliver,
+		retryDelay: cfg.RetryDelay,
+		logger:     logger.Named("NATSConsumer"),
+	}, nil
+}
+
+// ensureStream creates the stream capturing topic unless it already exists, so
+// the consumer can start before the first producer.
+func ensureStream(ctx context.Context, js jetstream.JetStream, stream, topic string) error {
+	_, err := js.Stream(ctx, stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("failed to look up stream %s: %w", stream, err)
+	}
+
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     stream,
+		Subjects: []string{topic},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("failed to create stream %s: %w", stream, err)
+	}
+	return nil
+}
+
+func (c *NATSConsumer) Consume(ctx context.Context, workerCount int, handler func(ctx context.Context, msg *Message) error) error {
+	if err := ensureStream(ctx, c.js, c.stream, c.topic); err != nil {
+		return err
+	}
+
+	consumer, err := c.js.CreateOrUpdateConsumer(ctx, c.stream, jetstream.ConsumerConfig{
+		Durable:       c.groupID,
+		FilterSubject: c.topic,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxDeliver:    c.maxDeliver,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consumer %s: %w", c.groupID, err)
+	}
+
+	messages, err := consumer.Messages(jetstream.PullMaxMessages(max(workerCount, 1)))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to topic %s: %w", c.topic, err)
+	}
+
+	tasks := make(chan jetstream.Msg)
+	for range workerCount {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			for msg := range tasks {
+				c.process(ctx, msg, handler)
+			}
+		}()
+	}
+
+	c.logger.Info("Started consuming messages from topic",
+		zap.String("topic", c.topic),
+		zap.String("stream", c.stream),
+	)
+
+	go func() {
+		<-ctx.Done()
+		messages.Stop()
+	}()
+
+	backoff := minErrorBackoff
+	for {
+		msg, err := messages.Next()
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+				break
+			}
+			c.logger.Error("Consumer error", zap.Error(err), zap.Duration("retry_in", backoff))
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, maxErrorBackoff)
+			continue
+		}
+		backoff = minErrorBackoff
+
+		select {
+		case tasks <- msg:
+		case <-ctx.Done():
+			// Not acked, the message is redelivered after the ack wait.
+		}
+	}
+
+	c.logger.Info("Stopping NATS consumer...")
+	close(tasks)
+	c.wg.Wait()
+
+	return ctx.Err()
+}
+
+func (c *NATSConsumer) process(ctx context.Context, msg jetstream.Msg, handler func(ctx context.Context, msg *Message) error) {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(TypeNATS),
+		semconv.MessagingConsumerGroupName(c.groupID),
+	}
+	var offset int64
+	var delivered uint64 = 1
+	if meta, err := msg.Metadata(); err == nil {
+		offset = int64(meta.Sequence.Stream)
+		delivered = meta.NumDelivered
+		attrs = append(attrs, attribute.Int64("messaging.nats.delivery_count", int64(delivered)))
+	}
+
+	headers := make(map[string]string, len(msg.Headers()))
+	for key, values := range msg.Headers() {
+		if len(values) > 0 {
+			headers[key] = values[len(values)-1]
+		}
+	}
+
+	var key []byte
+	if k, ok := headers[KeyHeader]; ok {
+		key = []byte(k)
+	}
+
+	err := process(ctx, &Message{
+		Topic:   msg.Subject(),
+		Offset:  offset,
+		Key:     key,
+		Value:   msg.Data(),
+		Headers: headers,
+	}, handler, c.logger, attrs...)
+
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			c.logger.Error("Failed to ack message", zap.Error(err), zap.Int64("offset", offset))
+		}
+		return
+	}
+
+	if delivered >= uint64(c.maxDeliver) {
+		c.logger.Error("Dropping message after the last delivery attempt",
+			zap.Int64("offset", offset),
+			zap.Uint64("deliveries", delivered),
+		)
+		if err := msg.Term(); err != nil {
+			c.logger.Error("Failed to terminate message", zap.Error(err), zap.Int64("offset", offset))
+		}
+		return
+	}
+
+	if err := msg.NakWithDelay(c.retryDelay); err != nil {
+		c.logger.Error("Failed to nak message", zap.Error(err), zap.Int64("offset", offset))
+	}
+}
+
+func (c *NATSConsumer) Close() error {
+	c.wg.Wait()
+	return c.conn.Drain()
+}
@@ -0,0 +1,183 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// runNATSServer starts an embedded JetStream enabled server and returns its URL.
+func runNATSServer(t *testing.T) string {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server didn't start")
+	}
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+	return srv.ClientURL()
+}
+
+func publish(t *testing.T, url string, values ...string) {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, ensureStream(ctx, js, "PRODUCT_EVENTS", "product-events"))
+	for _, value := range values {
+		msg := nats.NewMsg("product-events")
+		msg.Data = []byte(value)
+		msg.Header.Set(KeyHeader, "key-"+value)
+		msg.Header.Set(ProducerHeader, "product-service")
+		_, err := js.PublishMsg(ctx, msg)
+		require.NoError(t, err)
+	}
+}
+
+// consumeUntil runs a NATS consumer until done returns true for the delivered
+// values and no message is waiting for an ack.
+func consumeUntil(t *testing.T, cfg Config, handler func(msg *Message) error, done func(values []string) bool) []string {
+	t.Helper()
+
+	consumer, err := NewBroker(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var values []string
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- consumer.Consume(ctx, 2, func(ctx context.Context, msg *Message) error {
+			mu.Lock()
+			values = append(values, string(msg.Value))
+			mu.Unlock()
+			return handler(msg)
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return done(values)
+	}, 5*time.Second, 10*time.Millisecond)
+	// The handler returns before its message is acked.
+	assert.Eventually(t, func() bool {
+		_, ackPending := pendingMessages(t, cfg.Endpoint, cfg.GroupID)
+		return ackPending == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+	require.NoError(t, consumer.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	return values
+}
+
+func pendingMessages(t *testing.T, url, groupID string) (pending uint64, ackPending int) {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	consumer, err := js.Consumer(context.Background(), "PRODUCT_EVENTS", groupID)
+	require.NoError(t, err)
+	info, err := consumer.Info(context.Background())
+	require.NoError(t, err)
+	return info.NumPending, info.NumAckPending
+}
+
+func TestNATSConsumer(t *testing.T) {
+	url := runNATSServer(t)
+	cfg := Config{
+		Type:       TypeNATS,
+		Endpoint:   url,
+		Topic:      "product-events",
+		GroupID:    "notifications",
+		Stream:     "PRODUCT_EVENTS",
+		MaxDeliver: 3,
+		RetryDelay: 20 * time.Millisecond,
+	}
+
+	t.Run("Redelivers Failed Messages", func(t *testing.T) {
+		publish(t, url, "a", "b")
+
+		var failed sync.Once
+		values := consumeUntil(t, cfg, func(msg *Message) error {
+			assert.Equal(t, "key-"+string(msg.Value), string(msg.Key))
+			assert.Equal(t, "product-service", msg.Headers[ProducerHeader])
+			assert.Equal(t, "product-events", msg.Topic)
+
+			var err error
+			if string(msg.Value) == "a" {
+				failed.Do(func() { err = errors.New("temporary failure") })
+			}
+			return err
+		}, func(values []string) bool {
+			return len(values) == 3
+		})
+
+		assert.ElementsMatch(t, []string{"a", "a", "b"}, values, "The failed message should be delivered again")
+		pending, ackPending := pendingMessages(t, url, cfg.GroupID)
+		assert.Zero(t, pending)
+		assert.Zero(t, ackPending)
+	})
+
+	t.Run("Resumes Durable Consumer", func(t *testing.T) {
+		publish(t, url, "c")
+
+		values := consumeUntil(t, cfg, func(msg *Message) error {
+			return nil
+		}, func(values []string) bool {
+			return len(values) == 1
+		})
+
+		assert.Equal(t, []string{"c"}, values, "Acked messages should not be delivered to the group again")
+	})
+
+	t.Run("Drops Message After Max Deliver", func(t *testing.T) {
+		publish(t, url, "d")
+
+		values := consumeUntil(t, cfg, func(msg *Message) error {
+			return errors.New("permanent failure")
+		}, func(values []string) bool {
+			return len(values) == cfg.MaxDeliver
+		})
+
+		assert.Equal(t, []string{"d", "d", "d"}, values)
+		pending, ackPending := pendingMessages(t, url, cfg.GroupID)
+		assert.Zero(t, pending)
+		assert.Zero(t, ackPending, "The message should be terminated after the last attempt")
+	})
+}
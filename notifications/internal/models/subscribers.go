@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Channels a subscriber address can use.
+const (
+	ChannelEmail = "email"
+	ChannelSlack = "slack"
+	ChannelTeams = "teams"
+	ChannelHTTP  = "http"
+)
+
+type Subscriber struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// TenantID limits the subscriber to the events of a tenant, empty means every tenant.
+	TenantID string `json:"tenant_id"`
+	// Rule selects the events the subscriber is notified of, empty means every event. See rules.Env for
+	// what it can refer to.
+	Rule      string              `json:"rule"`
+	Addresses []SubscriberAddress `json:"addresses"`
+	Active    bool                `json:"active"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type SubscriberAddress struct {
+	// Channel is email, slack, teams or http.
+	Channel string `json:"channel" binding:"required"`
+	// Address is an email address for email and a webhook URL otherwise.
+	Address string `json:"address" binding:"required"`
+}
+
+type CreateSubscriberDTO struct {
+	Name      string              `json:"name" binding:"required"`
+	TenantID  string              `json:"tenant_id"`
+	Rule      string              `json:"rule"`
+	Addresses []SubscriberAddress `json:"addresses" binding:"required,min=1,dive"`
+	// Active defaults to true.
+	Active *bool `json:"active"`
+}
+
+// UpdateSubscriberDTO changes the fields that are set.
+type UpdateSubscriberDTO struct {
+	Name      *string              `json:"name"`
+	TenantID  *string              `json:"tenant_id"`
+	Rule      *string              `json:"rule"`
+	Addresses *[]SubscriberAddress `json:"addresses" binding:"omitempty,min=1,dive"`
+	Active    *bool                `json:"active"`
+}
+
+type SubscriberIDDTO struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
@@ -0,0 +1,88 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	ChatFormatSlack = "slack"
+	ChatFormatTeams = "teams"
+)
+
+type ChatConfig struct {
+	// WebhookURL is the incoming webhook of the channel to post to.
+	WebhookURL string
+	// Format is slack or teams, Slack compatible chats like Mattermost take slack.
+	Format  string
+	Timeout time.Duration
+}
+
+// ChatNotifier posts messages to a chat incoming webhook.
+type ChatNotifier struct {
+	cfg    ChatConfig
+	client *http.Client
+}
+
+func NewChatNotifier(cfg ChatConfig) (*ChatNotifier, error) {
+	if cfg.WebhookURL == "" {
+		return nil, errors.New("chat webhook url is required")
+	}
+	if err := validateURL(cfg.WebhookURL); err != nil {
+		return nil, fmt.Errorf("chat webhook: %w", err)
+	}
+	if cfg.Format != ChatFormatSlack && cfg.Format != ChatFormatTeams {
+		return nil, fmt.Errorf("unknown chat format %q, expected %s or %s", cfg.Format, ChatFormatSlack, ChatFormatTeams)
+	}
+	return &ChatNotifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+func (n *ChatNotifier) Name() string {
+	return n.cfg.Format
+}
+
+func (n *ChatNotifier) Notify(ctx context.Context, msg *Message) error {
+	var payload any
+	if n.cfg.Format == ChatFormatTeams {
+		payload = teamsPayload(msg)
+	} else {
+		payload = slackPayload(msg)
+	}
+	return postJSON(ctx, n.client, n.cfg.WebhookURL, nil, payload)
+}
+
+// slackPayload uses Block Kit, text is the fallback for notifications.
+func slackPayload(msg *Message) map[string]any {
+	return map[string]any{
+		"text": msg.Subject,
+		"blocks": []map[string]any{
+			{"type": "header", "text": map[string]any{"type": "plain_text", "text": msg.Subject}},
+			{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": msg.Text}},
+		},
+	}
+}
+
+// teamsPayload is an Adaptive Card, as Teams workflow webhooks expect it.
+func teamsPayload(msg *Message) map[string]any {
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body": []map[string]any{
+					{"type": "TextBlock", "text": msg.Subject, "weight": "Bolder", "size": "Medium", "wrap": true},
+					{"type": "TextBlock", "text": msg.Text, "wrap": true},
+				},
+			},
+		}},
+	}
+}
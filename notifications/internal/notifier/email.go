@@ -0,0 +1,200 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+type EmailConfig struct {
+	Host string
+	Port string
+	// Username and Password enable PLAIN authentication, which net/smtp only
+	// allows over TLS or to localhost.
+	Username string
+	Password string
+	From     string
+	To       []string
+	Timeout  time.Duration
+}
+
+// EmailNotifier sends messages over SMTP, upgrading the connection with
+// STARTTLS when the server offers it.
+type EmailNotifier struct {
+	cfg  EmailConfig
+	from *mail.Address
+	to   []*mail.Address
+}
+
+func NewEmailNotifier(cfg EmailConfig) (*EmailNotifier, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", cfg.From, err)
+	}
+	if len(cfg.To) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+	to := make([]*mail.Address, 0, len(cfg.To))
+	for _, addr := range cfg.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", addr, err)
+		}
+		to = append(to, parsed)
+	}
+
+	return &EmailNotifier{cfg: cfg, from: from, to: to}, nil
+}
+
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, msg *Message) error {
+	body, err := n.compose(msg)
+	if err != nil {
+		return fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.cfg.Host, n.cfg.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// net/smtp has no context support, the deadline bounds the whole conversation.
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from.Address); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := client.Rcpt(to.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose builds the message, multipart/alternative when there is an HTML body.
+func (n *EmailNotifier) compose(msg *Message) ([]byte, error) {
+	to := make([]string, 0, len(n.to))
+	for _, addr := range n.to {
+		to = append(to, addr.String())
+	}
+
+	header := [][2]string{
+		{"From", n.from.String()},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(n.from.Address)},
+		{"MIME-Version", "1.0"},
+	}
+
+	var body bytes.Buffer
+	if msg.HTML == "" {
+		header = append(header, [2]string{"Content-Type", "text/plain; charset=utf-8"}, [2]string{"Content-Transfer-Encoding", "quoted-printable"})
+		if err := writeQuotedPrintable(&body, msg.Text); err != nil {
+			return nil, err
+		}
+	} else {
+		mw := multipart.NewWriter(&body)
+		header = append(header, [2]string{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()})
+		for _, part := range [][2]string{
+			{"text/plain; charset=utf-8", msg.Text},
+			{"text/html; charset=utf-8", msg.HTML},
+		} {
+			pw, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part[0]},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(pw, part[1]); err != nil {
+				return nil, err
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, field := range header {
+		fmt.Fprintf(&buf, "%s: %s\r\n", field[0], field[1])
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
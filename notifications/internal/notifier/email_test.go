@@ -0,0 +1,235 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpServer is a minimal SMTP stand-in that records the mails it accepts.
+// rejectRcpt makes it refuse every recipient.
+type smtpServer struct {
+	addr       string
+	rejectRcpt bool
+
+	mu    sync.Mutex
+	mails []receivedMail
+}
+
+type receivedMail struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T, rejectRcpt bool) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := &smtpServer{addr: listener.Addr().String(), rejectRcpt: rejectRcpt}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	var mail receivedMail
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250-8BITMIME")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			_, credentials, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(credentials)
+			mail.auth = string(decoded)
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			mail.from = pathAddress(arg)
+			reply("250 OK")
+		case "RCPT":
+			if s.rejectRcpt {
+				reply("550 5.1.1 No such user")
+				continue
+			}
+			mail.to = append(mail.to, pathAddress(arg))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			mail.data = data.String()
+			s.mu.Lock()
+			s.mails = append(s.mails, mail)
+			s.mu.Unlock()
+			mail = receivedMail{}
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// pathAddress returns the address of a "FROM:<addr> PARAMS" argument.
+func pathAddress(arg string) string {
+	_, rest, _ := strings.Cut(arg, "<")
+	addr, _, _ := strings.Cut(rest, ">")
+	return addr
+}
+
+func (s *smtpServer) received() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.mails...)
+}
+
+func newTestEmailNotifier(t *testing.T, s *smtpServer, username string) *EmailNotifier {
+	host, port, err := net.SplitHostPort(s.addr)
+	require.NoError(t, err)
+
+	n, err := NewEmailNotifier(EmailConfig{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: "secret",
+		From:     "Products <products@example.com>",
+		To:       []string{"ops@example.com", "Sales Team <sales@example.com>"},
+		Timeout:  5 * time.Second,
+	})
+	require.NoError(t, err)
+	return n
+}
+
+func TestEmailNotifier(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Plain Text", func(t *testing.T) {
+		s := newSMTPServer(t, false)
+		n := newTestEmailNotifier(t, s, "")
+
+		require.NoError(t, n.Notify(ctx, &Message{Subject: "Product created: Grüner Tee", Text: "Product \"Grüner Tee\" was created."}))
+
+		mails := s.received()
+		require.Len(t, mails, 1)
+		assert.Empty(t, mails[0].auth)
+		assert.Equal(t, "products@example.com", mails[0].from)
+		assert.Equal(t, []string{"ops@example.com", "sales@example.com"}, mails[0].to)
+
+		parsed, err := mail.ReadMessage(strings.NewReader(mails[0].data))
+		require.NoError(t, err)
+		subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, "Product created: Grüner Tee", subject)
+		assert.Equal(t, `"Products" <products@example.com>`, parsed.Header.Get("From"))
+		assert.Equal(t, "text/plain; charset=utf-8", parsed.Header.Get("Content-Type"))
+		assert.Contains(t, mails[0].data, "Gr=C3=BCner Tee")
+	})
+
+	t.Run("HTML With Auth", func(t *testing.T) {
+		s := newSMTPServer(t, false)
+		n := newTestEmailNotifier(t, s, "mailer")
+
+		require.NoError(t, n.Notify(ctx, &Message{Subject: "Product created", Text: "plain body", HTML: "<p>html body</p>"}))
+
+		mails := s.received()
+		require.Len(t, mails, 1)
+		assert.Equal(t, "\x00mailer\x00secret", mails[0].auth)
+
+		parsed, err := mail.ReadMessage(strings.NewReader(mails[0].data))
+		require.NoError(t, err)
+		mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		mr := multipart.NewReader(parsed.Body, params["boundary"])
+		var bodies []string
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			body, err := io.ReadAll(part)
+			require.NoError(t, err)
+			bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(body))
+		}
+		assert.Equal(t, []string{"text/plain; charset=utf-8: plain body", "text/html; charset=utf-8: <p>html body</p>"}, bodies)
+	})
+
+	t.Run("Rejected Recipient", func(t *testing.T) {
+		s := newSMTPServer(t, true)
+		n := newTestEmailNotifier(t, s, "")
+
+		err := n.Notify(ctx, &Message{Subject: "Product created", Text: "body"})
+
+		assert.ErrorContains(t, err, "No such user")
+		assert.Empty(t, s.received())
+	})
+}
+
+func TestNewEmailNotifier(t *testing.T) {
+	type testCase struct {
+		name string
+		cfg  EmailConfig
+	}
+
+	cases := []testCase{
+		{name: "Missing Host", cfg: EmailConfig{From: "products@example.com", To: []string{"ops@example.com"}}},
+		{name: "Invalid From", cfg: EmailConfig{Host: "localhost", From: "products", To: []string{"ops@example.com"}}},
+		{name: "No Recipients", cfg: EmailConfig{Host: "localhost", From: "products@example.com"}},
+		{name: "Invalid Recipient", cfg: EmailConfig{Host: "localhost", From: "products@example.com", To: []string{"ops"}}},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, err := NewEmailNotifier(tCase.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
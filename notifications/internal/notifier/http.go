@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"context"
+	"contracts/productevent"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type HTTPConfig struct {
+	URL string
+	// Authorization is sent as the Authorization header when set.
+	Authorization string
+	Timeout       time.Duration
+}
+
+// HTTPNotifier posts messages as JSON with the event as a CloudEvent, for
+// receivers that build their own notifications.
+type HTTPNotifier struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+type httpPayload struct {
+	Subject string          `json:"subject"`
+	Text    string          `json:"text"`
+	HTML    string          `json:"html,omitempty"`
+	Event   json.RawMessage `json:"event,omitempty"`
+}
+
+func NewHTTPNotifier(cfg HTTPConfig) (*HTTPNotifier, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("http notifier url is required")
+	}
+	if err := validateURL(cfg.URL); err != nil {
+		return nil, fmt.Errorf("http notifier: %w", err)
+	}
+	return &HTTPNotifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+func (n *HTTPNotifier) Name() string {
+	return "http"
+}
+
+func (n *HTTPNotifier) Notify(ctx context.Context, msg *Message) error {
+	payload := httpPayload{Subject: msg.Subject, Text: msg.Text, HTML: msg.HTML}
+	if msg.Event != nil {
+		event, err := productevent.EncodeJSON(msg.Event)
+		if err != nil {
+			return err
+		}
+		payload.Event = event
+	}
+
+	header := http.Header{}
+	if n.cfg.Authorization != "" {
+		header.Set("Authorization", n.cfg.Authorization)
+	}
+	return postJSON(ctx, n.client, n.cfg.URL, header, payload)
+}
@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"context"
+	"contracts/productevent"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver answers every request with status and passes it on to the returned channel.
+func newReceiver(t *testing.T, status int) (*httptest.Server, <-chan receivedRequest) {
+	requests := make(chan receivedRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- receivedRequest{header: r.Header, body: body}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("receiver says " + http.StatusText(status)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func testMessage() *Message {
+	event := productevent.New(productevent.Created, productevent.Product{ID: "p1", Name: "Test Product", Price: 12345}, productevent.Metadata{TenantID: "acme"})
+	return &Message{Subject: "Product created: Test Product", Text: "Product \"Test Product\" was created.", Event: event}
+}
+
+func TestHTTPNotifier(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		srv, requests := newReceiver(t, http.StatusAccepted)
+		n, err := NewHTTPNotifier(HTTPConfig{URL: srv.URL, Authorization: "Bearer token", Timeout: time.Second})
+		require.NoError(t, err)
+		msg := testMessage()
+
+		require.NoError(t, n.Notify(ctx, msg))
+
+		req := <-requests
+		assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", req.header.Get("Authorization"))
+
+		var payload httpPayload
+		require.NoError(t, json.Unmarshal(req.body, &payload))
+		assert.Equal(t, msg.Subject, payload.Subject)
+		assert.Equal(t, msg.Text, payload.Text)
+		event, err := productevent.DecodeJSON(payload.Event)
+		require.NoError(t, err)
+		assert.Equal(t, msg.Event.ID, event.ID)
+		assert.Equal(t, productevent.Cents(12345), event.Product.Price)
+	})
+
+	t.Run("Error Status", func(t *testing.T) {
+		srv, _ := newReceiver(t, http.StatusBadGateway)
+		n, err := NewHTTPNotifier(HTTPConfig{URL: srv.URL, Timeout: time.Second})
+		require.NoError(t, err)
+
+		err = n.Notify(ctx, testMessage())
+
+		assert.ErrorContains(t, err, "unexpected response status 502: receiver says Bad Gateway")
+	})
+}
+
+func TestChatNotifier(t *testing.T) {
+	ctx := context.Background()
+
+	type testCase struct {
+		format   string
+		expected string
+	}
+
+	cases := []testCase{
+		{
+			format: ChatFormatSlack,
+			expected: `{"text":"Product created: Test Product","blocks":[
+				{"type":"header","text":{"type":"plain_text","text":"Product created: Test Product"}},
+				{"type":"section","text":{"type":"mrkdwn","text":"Product \"Test Product\" was created."}}]}`,
+		},
+		{
+			format: ChatFormatTeams,
+			expected: `{"type":"message","attachments":[{"contentType":"application/vnd.microsoft.card.adaptive","content":{
+				"$schema":"http://adaptivecards.io/schemas/adaptive-card.json","type":"AdaptiveCard","version":"1.4","body":[
+				{"type":"TextBlock","text":"Product created: Test Product","weight":"Bolder","size":"Medium","wrap":true},
+				{"type":"TextBlock","text":"Product \"Test Product\" was created.","wrap":true}]}}]}`,
+		},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.format, func(t *testing.T) {
+			srv, requests := newReceiver(t, http.StatusOK)
+			n, err := NewChatNotifier(ChatConfig{WebhookURL: srv.URL, Format: tCase.format, Timeout: time.Second})
+			require.NoError(t, err)
+
+			require.NoError(t, n.Notify(ctx, testMessage()))
+
+			assert.JSONEq(t, tCase.expected, string((<-requests).body))
+			assert.Equal(t, tCase.format, n.Name())
+		})
+	}
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		_, err := NewChatNotifier(ChatConfig{WebhookURL: "https://chat.example.com/hook", Format: "irc"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		for _, webhookURL := range []string{"chat.example.com/hook", "ftp://chat.example.com/hook", "https://"} {
+			_, err := NewChatNotifier(ChatConfig{WebhookURL: webhookURL, Format: ChatFormatSlack})
+			assert.Error(t, err, webhookURL)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Package notifier sends notifications over channels such as email, chat
+// webhooks and plain HTTP.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"contracts/productevent"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// maxResponseBody is how much of an error response body ends up in the error.
+const maxResponseBody = 1 << 10
+
+// Message is a notification about a product event.
+type Message struct {
+	Subject string
+	Text    string
+	// HTML is an optional rich body, channels that can't show it use Text.
+	HTML  string
+	Event *productevent.Event
+}
+
+// Notifier delivers messages over one channel. Notify returns an error when the
+// message wasn't accepted, sending it again may notify twice.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// validateURL checks that rawURL is an absolute http or https URL.
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// postJSON posts body as JSON and treats any status but 2xx as an error.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "notifications/1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package sqlite stores the notification service's own data in a local SQLite
+// database.
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+// schema is applied on every start, so changes to it have to be idempotent.
+const schema = `
+CREATE TABLE IF NOT EXISTS subscribers (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  tenant_id TEXT NOT NULL DEFAULT '',
+  rule TEXT NOT NULL DEFAULT '',
+  addresses TEXT NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS subscribers_active_idx ON subscribers (active);
+`
+
+// Open opens the database at path, creating it if needed, and applies the schema.
+func Open(ctx context.Context, path string) (*sqlx.DB, error) {
+	dsn := (&url.URL{
+		Scheme: "file",
+		Opaque: path,
+		RawQuery: url.Values{"_pragma": {
+			"busy_timeout(5000)",
+			"journal_mode(WAL)",
+			"foreign_keys(ON)",
+		}}.Encode(),
+	}).String()
+
+	db, err := sqlx.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer, one connection keeps writes from failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to apply schema to %s: %w", path, err)
+	}
+	return db, nil
+}
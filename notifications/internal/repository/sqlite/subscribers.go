@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"notifications/internal/apperrors"
+	"notifications/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const subscriberColumns = `id, name, tenant_id, rule, addresses, active, created_at, updated_at`
+
+type SubscribersRepository struct {
+	db *sqlx.DB
+}
+
+func NewSubscribersRepository(db *sqlx.DB) *SubscribersRepository {
+	return &SubscribersRepository{
+		db: db,
+	}
+}
+
+// addresses is stored as a JSON array.
+type addresses []models.SubscriberAddress
+
+func (a addresses) Value() (driver.Value, error) {
+	if a == nil {
+		a = addresses{}
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *addresses) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), a)
+	case []byte:
+		return json.Unmarshal(src, a)
+	default:
+		return errors.New("subscriber addresses must be a JSON string")
+	}
+}
+
+type subscriberRow struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	TenantID  string    `db:"tenant_id"`
+	Rule      string    `db:"rule"`
+	Addresses addresses `db:"addresses"`
+	Active    bool      `db:"active"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r *subscriberRow) toModel() *models.Subscriber {
+	return &models.Subscriber{
+		ID:        r.ID,
+		Name:      r.Name,
+		TenantID:  r.TenantID,
+		Rule:      r.Rule,
+		Addresses: []models.SubscriberAddress(r.Addresses),
+		Active:    r.Active,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func (r *SubscribersRepository) Create(ctx context.Context, sub *models.Subscriber) (*models.Subscriber, error) {
+	now := time.Now().UTC()
+
+	var query = `
+		INSERT INTO subscribers (` + subscriberColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + subscriberColumns
+	var row subscriberRow
+	err := r.db.GetContext(ctx, &row, query, uuid.NewString(), sub.Name, sub.TenantID, sub.Rule, addresses(sub.Addresses), sub.Active, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *SubscribersRepository) Get(ctx context.Context, id string) (*models.Subscriber, error) {
+	var query = `SELECT ` + subscriberColumns + ` FROM subscribers WHERE id = ?`
+	var row subscriberRow
+	err := r.db.GetContext(ctx, &row, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrSubscriberNotFound
+		}
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *SubscribersRepository) List(ctx context.Context) ([]models.Subscriber, error) {
+	var query = `SELECT ` + subscriberColumns + ` FROM subscribers ORDER BY created_at, id`
+	return r.selectSubscribers(ctx, query)
+}
+
+func (r *SubscribersRepository) ListActive(ctx context.Context) ([]models.Subscriber, error) {
+	var query = `SELECT ` + subscriberColumns + ` FROM subscribers WHERE active ORDER BY created_at, id`
+	return r.selectSubscribers(ctx, query)
+}
+
+func (r *SubscribersRepository) selectSubscribers(ctx context.Context, query string, args ...any) ([]models.Subscriber, error) {
+	var rows []subscriberRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscriber, 0, len(rows))
+	for i := range rows {
+		subs = append(subs, *rows[i].toModel())
+	}
+	return subs, nil
+}
+
+func (r *SubscribersRepository) Update(ctx context.Context, id string, update *models.UpdateSubscriberDTO) (*models.Subscriber, error) {
+	var addrs *addresses
+	if update.Addresses != nil {
+		a := addresses(*update.Addresses)
+		addrs = &a
+	}
+
+	var query = `
+		UPDATE subscribers SET
+		  name = COALESCE(?, name),
+		  tenant_id = COALESCE(?, tenant_id),
+		  rule = COALESCE(?, rule),
+		  addresses = COALESCE(?, addresses),
+		  active = COALESCE(?, active),
+		  updated_at = ?
+		WHERE id = ?
+		RETURNING ` + subscriberColumns
+	var row subscriberRow
+	err := r.db.GetContext(ctx, &row, query, update.Name, update.TenantID, update.Rule, addrs, update.Active, time.Now().UTC(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrSubscriberNotFound
+		}
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *SubscribersRepository) Delete(ctx context.Context, id string) (*models.Subscriber, error) {
+	var query = `DELETE FROM subscribers WHERE id = ? RETURNING ` + subscriberColumns
+	var row subscriberRow
+	err := r.db.GetContext(ctx, &row, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrSubscriberNotFound
+		}
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"notifications/internal/apperrors"
+	"notifications/internal/models"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribersRepository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "notifications.db")
+	db, err := Open(ctx, path)
+	require.NoError(t, err)
+	repo := NewSubscribersRepository(db)
+
+	created, err := repo.Create(ctx, &models.Subscriber{
+		Name:      "Sales",
+		Rule:      `product.price > 10000`,
+		Addresses: []models.SubscriberAddress{{Channel: models.ChannelEmail, Address: "sales@example.com"}},
+		Active:    true,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	inactive, err := repo.Create(ctx, &models.Subscriber{
+		Name:      "Ops",
+		TenantID:  "acme",
+		Addresses: []models.SubscriberAddress{{Channel: models.ChannelSlack, Address: "https://hooks.slack.com/services/x"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := repo.Get(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, got)
+
+		_, err = repo.Get(ctx, "00000000-0000-0000-0000-000000000000")
+		assert.ErrorIs(t, err, apperrors.ErrSubscriberNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		all, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		active, err := repo.ListActive(ctx)
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+		assert.Equal(t, created.ID, active[0].ID)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		rule := `tenant_id == "acme"`
+		activate := true
+		updated, err := repo.Update(ctx, inactive.ID, &models.UpdateSubscriberDTO{Rule: &rule, Active: &activate})
+		require.NoError(t, err)
+		assert.Equal(t, rule, updated.Rule)
+		assert.True(t, updated.Active)
+		assert.Equal(t, "Ops", updated.Name)
+		assert.Equal(t, inactive.Addresses, updated.Addresses)
+
+		_, err = repo.Update(ctx, "00000000-0000-0000-0000-000000000000", &models.UpdateSubscriberDTO{Active: &activate})
+		assert.ErrorIs(t, err, apperrors.ErrSubscriberNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		deleted, err := repo.Delete(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, deleted.ID)
+
+		_, err = repo.Delete(ctx, created.ID)
+		assert.ErrorIs(t, err, apperrors.ErrSubscriberNotFound)
+	})
+
+	t.Run("Persisted", func(t *testing.T) {
+		require.NoError(t, db.Close())
+		db, err := Open(ctx, path)
+		require.NoError(t, err)
+		defer db.Close()
+
+		all, err := NewSubscribersRepository(db).List(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, inactive.ID, all[0].ID)
+	})
+}
@@ -0,0 +1,17 @@
+package requestid
+
+import "context"
+
+// MessageHeader carries the ID of the request that caused a message.
+const MessageHeader = "request-id"
+
+type requestIDKey struct{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
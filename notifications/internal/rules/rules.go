@@ -0,0 +1,98 @@
+// Package rules evaluates the expressions subscribers select product events
+// with, e.g. `event_type == "product_created" && product.price > 10000`.
+//
+// Expressions are compiled with expr (https://expr-lang.org), which only reads
+// the event: there is no assignment, I/O or unbounded loop, and compilation and
+// evaluation are capped in size and memory.
+package rules
+
+import (
+	"contracts/productevent"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+const (
+	// MaxLength caps the length of an expression.
+	MaxLength = 1000
+	// maxNodes caps the size of a compiled expression.
+	maxNodes = 200
+)
+
+// Env is what expressions see of an event.
+type Env struct {
+	// EventType is product_created or product_deleted.
+	EventType string    `expr:"event_type"`
+	TenantID  string    `expr:"tenant_id"`
+	Actor     string    `expr:"actor"`
+	Time      time.Time `expr:"time"`
+	Product   Product   `expr:"product"`
+}
+
+type Product struct {
+	ID          string `expr:"id"`
+	Name        string `expr:"name"`
+	Description string `expr:"description"`
+	// Price is in cents.
+	Price     int64     `expr:"price"`
+	CreatedAt time.Time `expr:"created_at"`
+}
+
+// Rule is a compiled expression.
+type Rule struct {
+	program *vm.Program
+}
+
+// Compile checks that expression is a boolean over Env. An empty expression
+// matches every event.
+func Compile(expression string) (*Rule, error) {
+	if expression == "" {
+		return &Rule{}, nil
+	}
+	if len(expression) > MaxLength {
+		return nil, fmt.Errorf("rule is longer than %d characters", MaxLength)
+	}
+
+	program, err := expr.Compile(expression, expr.Env(Env{}), expr.AsBool(), expr.MaxNodes(maxNodes))
+	if err != nil {
+		return nil, err
+	}
+	return &Rule{program: program}, nil
+}
+
+// Match evaluates the rule against event.
+func (r *Rule) Match(event *productevent.Event) (bool, error) {
+	if r.program == nil {
+		return true, nil
+	}
+
+	out, err := expr.Run(r.program, NewEnv(event))
+	if err != nil {
+		return false, err
+	}
+	matched, ok := out.(bool)
+	if !ok {
+		return false, errors.New("rule did not evaluate to a boolean")
+	}
+	return matched, nil
+}
+
+func NewEnv(event *productevent.Event) Env {
+	return Env{
+		EventType: string(event.Type),
+		TenantID:  event.TenantID,
+		Actor:     event.Actor,
+		Time:      event.Time,
+		Product: Product{
+			ID:          event.Product.ID,
+			Name:        event.Product.Name,
+			Description: event.Product.Description,
+			Price:       int64(event.Product.Price),
+			CreatedAt:   event.Product.CreatedAt,
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"contracts/productevent"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMatch(t *testing.T) {
+	type testCase struct {
+		name    string
+		rule    string
+		matched bool
+	}
+
+	event := productevent.New(productevent.Created, productevent.Product{
+		ID:    "p1",
+		Name:  "Espresso Machine",
+		Price: 25000,
+	}, productevent.Metadata{TenantID: "acme", Actor: "alice"})
+
+	cases := []testCase{
+		{name: "Empty Rule", rule: "", matched: true},
+		{name: "Type And Price", rule: `event_type == "product_created" && product.price > 10000`, matched: true},
+		{name: "Price Too Low", rule: `product.price > 100000`, matched: false},
+		{name: "Other Type", rule: `event_type == "product_deleted"`, matched: false},
+		{name: "Tenant List", rule: `tenant_id in ["acme", "globex"]`, matched: true},
+		{name: "Name Contains", rule: `product.name contains "Espresso" or actor == "bob"`, matched: true},
+		{name: "Name Prefix", rule: `lower(product.name) startsWith "milk"`, matched: false},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			rule, err := Compile(tCase.rule)
+			require.NoError(t, err)
+
+			matched, err := rule.Match(event)
+			require.NoError(t, err)
+			assert.Equal(t, tCase.matched, matched)
+		})
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	type testCase struct {
+		name string
+		rule string
+	}
+
+	cases := []testCase{
+		{name: "Syntax Error", rule: `event_type ==`},
+		{name: "Unknown Variable", rule: `product.color == "red"`},
+		{name: "Not A Boolean", rule: `product.price + 1`},
+		{name: "Type Mismatch", rule: `product.price > "100"`},
+		{name: "Too Long", rule: strings.Repeat(`actor == "a" || `, 100) + `actor == "a"`},
+		{name: "Too Many Nodes", rule: strings.Repeat("1 + ", 150) + "1 > 0"},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, err := Compile(tCase.rule)
+			assert.Error(t, err)
+		})
+	}
+}
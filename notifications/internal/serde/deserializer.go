@@ -0,0 +1,162 @@
+// Package serde decodes product events published as JSON CloudEvents, or as
+// Protobuf or Avro in the Confluent wire format.
+package serde
+
+import (
+	"context"
+	"contracts/productevent"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var (
+	ProtobufSchema = Schema{Type: SchemaTypeProtobuf, Schema: productevent.ProtobufSchema}
+	AvroSchema     = Schema{Type: SchemaTypeAvro, Schema: productevent.AvroSchema}
+)
+
+// magicByte starts messages in the Confluent wire format, followed by the
+// schema id as a big endian uint32 and the payload.
+const magicByte byte = 0
+
+type decodeFunc func(payload []byte) (*productevent.Event, error)
+
+type Deserializer struct {
+	registry Registry
+
+	mu       sync.Mutex
+	decoders map[int]decodeFunc
+}
+
+// NewDeserializer creates a deserializer resolving schema ids through registry.
+// Without a registry only JSON events can be decoded.
+func NewDeserializer(registry Registry) *Deserializer {
+	return &Deserializer{
+		registry: registry,
+		decoders: map[int]decodeFunc{},
+	}
+}
+
+// Deserialize decodes a product event in any of the supported formats.
+func (d *Deserializer) Deserialize(ctx context.Context, message []byte) (*productevent.Event, error) {
+	if len(message) == 0 || message[0] != magicByte {
+		return productevent.DecodeJSON(message)
+	}
+	if len(message) < 5 {
+		return nil, fmt.Errorf("message too short for the wire format")
+	}
+
+	decode, err := d.decoder(ctx, int(binary.BigEndian.Uint32(message[1:5])))
+	if err != nil {
+		return nil, err
+	}
+	return decode(message[5:])
+}
+
+func (d *Deserializer) decoder(ctx context.Context, schemaID int) (decodeFunc, error) {
+	d.mu.Lock()
+	decode, ok := d.decoders[schemaID]
+	d.mu.Unlock()
+	if ok {
+		return decode, nil
+	}
+
+	if d.registry == nil {
+		return nil, fmt.Errorf("schema registry is not configured, can't resolve schema id %d", schemaID)
+	}
+	schema, err := d.registry.SchemaByID(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch schema.Type {
+	case SchemaTypeProtobuf:
+		decode = decodeProtobuf
+	case SchemaTypeAvro:
+		decoder, err := productevent.NewAvroDecoder(schema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("schema %d: %w", schemaID, err)
+		}
+		decode = decoder.Decode
+	default:
+		return nil, fmt.Errorf("unsupported schema type %s", schema.Type)
+	}
+
+	d.mu.Lock()
+	d.decoders[schemaID] = decode
+	d.mu.Unlock()
+	return decode, nil
+}
+
+// CheckCompatibility makes sure the reader schema for the format of the latest
+// version of subject can read it. JSON subjects and unknown ones are accepted.
+func (d *Deserializer) CheckCompatibility(ctx context.Context, subject string) error {
+	if d.registry == nil {
+		return nil
+	}
+
+	latest, err := d.registry.LatestSchema(ctx, subject)
+	if errors.Is(err, ErrSubjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var reader Schema
+	switch latest.Type {
+	case SchemaTypeProtobuf:
+		reader = ProtobufSchema
+	case SchemaTypeAvro:
+		reader = AvroSchema
+	default:
+		return nil
+	}
+
+	compatible, err := d.registry.Compatible(ctx, subject, reader)
+	if err != nil {
+		return err
+	}
+	if !compatible {
+		return fmt.Errorf("reader schema can't read the latest %s schema of subject %s", latest.Type, subject)
+	}
+	return nil
+}
+
+// decodeProtobuf decodes a products.events.v1.ProductEvent message in the wire format.
+func decodeProtobuf(payload []byte) (*productevent.Event, error) {
+	message, err := skipMessageIndexes(payload)
+	if err != nil {
+		return nil, err
+	}
+	return productevent.DecodeProtobuf(message)
+}
+
+// skipMessageIndexes consumes the message index list of the wire format and
+// makes sure it points to the first message of the schema, ProductEvent.
+func skipMessageIndexes(payload []byte) ([]byte, error) {
+	count, n := protowire.ConsumeVarint(payload)
+	if n < 0 {
+		return nil, protowire.ParseError(n)
+	}
+	payload = payload[n:]
+
+	// An empty list is the shorthand for [0].
+	if protowire.DecodeZigZag(count) == 0 {
+		return payload, nil
+	}
+	for i := int64(0); i < protowire.DecodeZigZag(count); i++ {
+		index, n := protowire.ConsumeVarint(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		if i == 0 && protowire.DecodeZigZag(index) != 0 {
+			return nil, fmt.Errorf("unexpected message index %d", protowire.DecodeZigZag(index))
+		}
+		payload = payload[n:]
+	}
+	return payload, nil
+}
@@ -0,0 +1,108 @@
+package serde
+
+import (
+	"context"
+	"contracts/productevent"
+	"contracts/productevent/eventtest"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry serves schemas by id over the schema registry API. Schema 1 is
+// the protobuf schema and schema 2 the avro one.
+func fakeRegistry(t *testing.T, compatible bool) *HTTPRegistry {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /schemas/ids/1", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"schema": productevent.ProtobufSchema, "schemaType": "PROTOBUF"})
+	})
+	mux.HandleFunc("GET /schemas/ids/2", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"schema": productevent.AvroSchema})
+	})
+	mux.HandleFunc("GET /subjects/product-events-value/versions/latest", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 2, "version": 1, "schema": productevent.AvroSchema})
+	})
+	mux.HandleFunc("POST /compatibility/subjects/product-events-value/versions/latest", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]bool{"is_compatible": compatible})
+	})
+	mux.HandleFunc("/schemas/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error_code": 40403, "message": "Schema not found"})
+	})
+	mux.HandleFunc("/subjects/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error_code": 40401, "message": "Subject not found"})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewHTTPRegistry(server.URL)
+}
+
+func wireHeader(schemaID int) []byte {
+	return binary.BigEndian.AppendUint32([]byte{magicByte}, uint32(schemaID))
+}
+
+// TestDeserialize decodes the golden encodings of the contract, which the
+// products serializers are tested to write.
+func TestDeserialize(t *testing.T) {
+	type testCase struct {
+		name    string
+		message []byte
+	}
+
+	cases := []testCase{
+		{name: "JSON", message: eventtest.JSON},
+		{name: "Legacy JSON", message: eventtest.LegacyJSON},
+		{name: "Protobuf", message: slices.Concat(wireHeader(1), []byte{0}, eventtest.Protobuf)},
+		{name: "Protobuf Explicit Message Index", message: slices.Concat(wireHeader(1), []byte{2, 0}, eventtest.Protobuf)},
+		{name: "Avro", message: slices.Concat(wireHeader(2), eventtest.Avro)},
+	}
+
+	deserializer := NewDeserializer(fakeRegistry(t, true))
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// The second round uses the cached decoder.
+			for range 2 {
+				event, err := deserializer.Deserialize(context.Background(), tCase.message)
+
+				require.NoError(t, err)
+				assert.Equal(t, eventtest.Event().Product, event.Product)
+				assert.Equal(t, eventtest.Event().Type, event.Type)
+			}
+		})
+	}
+}
+
+func TestDeserializeErrors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewDeserializer(nil).Deserialize(ctx, append(wireHeader(1), 0))
+	assert.Error(t, err, "Wire format events need a registry")
+
+	_, err = NewDeserializer(fakeRegistry(t, true)).Deserialize(ctx, append(wireHeader(9), 0))
+	assert.ErrorIs(t, err, ErrSchemaNotFound)
+
+	_, err = NewDeserializer(fakeRegistry(t, true)).Deserialize(ctx, []byte{magicByte, 0})
+	assert.Error(t, err)
+
+	_, err = NewDeserializer(fakeRegistry(t, true)).Deserialize(ctx, slices.Concat(wireHeader(1), []byte{2, 2}, eventtest.Protobuf))
+	assert.Error(t, err, "Only the first message of the schema is a product event")
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, NewDeserializer(fakeRegistry(t, true)).CheckCompatibility(ctx, "product-events-value"))
+	assert.Error(t, NewDeserializer(fakeRegistry(t, false)).CheckCompatibility(ctx, "product-events-value"))
+	assert.NoError(t, NewDeserializer(fakeRegistry(t, false)).CheckCompatibility(ctx, "unknown-value"), "Unknown subjects should be accepted")
+	assert.NoError(t, NewDeserializer(nil).CheckCompatibility(ctx, "product-events-value"))
+}
@@ -0,0 +1,162 @@
+package serde
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type SchemaType string
+
+const (
+	SchemaTypeAvro     SchemaType = "AVRO"
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+	SchemaTypeJSON     SchemaType = "JSON"
+)
+
+type Schema struct {
+	Type   SchemaType
+	Schema string
+}
+
+var (
+	ErrSchemaNotFound  = errors.New("schema not found")
+	ErrSubjectNotFound = errors.New("subject not found")
+)
+
+// Registry is the subset of the Confluent Schema Registry API the deserializer uses.
+type Registry interface {
+	SchemaByID(ctx context.Context, id int) (Schema, error)
+	LatestSchema(ctx context.Context, subject string) (Schema, error)
+	// Compatible reports whether schema can be registered as the next version of subject.
+	Compatible(ctx context.Context, subject string, schema Schema) (bool, error)
+}
+
+// HTTPRegistry talks to a Confluent compatible schema registry.
+type HTTPRegistry struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPRegistry(baseURL string) *HTTPRegistry {
+	return &HTTPRegistry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Error codes of the schema registry API.
+const (
+	errorCodeSubjectNotFound = 40401
+	errorCodeVersionNotFound = 40402
+	errorCodeSchemaNotFound  = 40403
+)
+
+type registryError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("schema registry: %d %s", e.ErrorCode, e.Message)
+}
+
+type schemaResponse struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+}
+
+func (r schemaResponse) toSchema() Schema {
+	// AVRO is the default and is left out by the registry.
+	if r.SchemaType == "" {
+		r.SchemaType = SchemaTypeAvro
+	}
+	return Schema{Type: r.SchemaType, Schema: r.Schema}
+}
+
+func (r *HTTPRegistry) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	var resp schemaResponse
+	err := r.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp)
+
+	var regErr *registryError
+	if errors.As(err, &regErr) && regErr.ErrorCode == errorCodeSchemaNotFound {
+		return Schema{}, fmt.Errorf("%w: id %d", ErrSchemaNotFound, id)
+	}
+	if err != nil {
+		return Schema{}, err
+	}
+	return resp.toSchema(), nil
+}
+
+func (r *HTTPRegistry) LatestSchema(ctx context.Context, subject string) (Schema, error) {
+	var resp schemaResponse
+	err := r.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil, &resp)
+
+	var regErr *registryError
+	if errors.As(err, &regErr) && (regErr.ErrorCode == errorCodeSubjectNotFound || regErr.ErrorCode == errorCodeVersionNotFound) {
+		return Schema{}, fmt.Errorf("%w: %s", ErrSubjectNotFound, subject)
+	}
+	if err != nil {
+		return Schema{}, err
+	}
+	return resp.toSchema(), nil
+}
+
+func (r *HTTPRegistry) Compatible(ctx context.Context, subject string, schema Schema) (bool, error) {
+	req := schemaResponse{Schema: schema.Schema, SchemaType: schema.Type}
+	if req.SchemaType == SchemaTypeAvro {
+		req.SchemaType = ""
+	}
+
+	var resp struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	err := r.do(ctx, http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest", req, &resp)
+
+	var regErr *registryError
+	if errors.As(err, &regErr) && (regErr.ErrorCode == errorCodeSubjectNotFound || regErr.ErrorCode == errorCodeVersionNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return resp.IsCompatible, nil
+}
+
+func (r *HTTPRegistry) do(ctx context.Context, method, path string, body, result any) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		regErr := &registryError{}
+		if err := json.NewDecoder(resp.Body).Decode(regErr); err != nil {
+			regErr.Message = resp.Status
+		}
+		return regErr
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
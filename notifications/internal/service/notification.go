@@ -2,48 +2,192 @@ package service
 
 import (
 	"context"
-	"notifications/internal/models"
+	"contracts/productevent"
+	"fmt"
+	loggerPkg "notifications/internal/logger"
+	"notifications/internal/notifier"
+	"slices"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("notifications/internal/service")
+
 type NotificationService interface {
-	HandleProductEvent(ctx context.Context, event *models.ProductEvent) error
+	HandleProductEvent(ctx context.Context, event *productevent.Event) error
+}
+
+// MessageRenderer renders the message of an event for a channel, false when
+// it has no template for them.
+type MessageRenderer interface {
+	Render(event *productevent.Event, channel string) (*notifier.Message, bool, error)
+}
+
+// RecipientMatcher returns the notifiers of the subscribers an event is for.
+type RecipientMatcher interface {
+	Match(event *productevent.Event) []notifier.Notifier
+}
+
+// RetryConfig controls how a failed notification is retried.
+type RetryConfig struct {
+	// Attempts is how often a notification is tried, at least once.
+	Attempts int
+	// Delay is the wait before the first retry, it doubles with every retry.
+	Delay time.Duration
 }
 
 type notificationService struct {
-	logger *zap.Logger
+	notifiers  []notifier.Notifier
+	recipients RecipientMatcher
+	renderer   MessageRenderer
+	retry      RetryConfig
+	logger     *zap.Logger
 }
 
-func NewNotificationService(logger *zap.Logger) NotificationService {
+// NewNotificationService creates the service, which sends every product event
+// over all notifiers and to the matching recipients, if recipients isn't nil.
+// Messages come from the renderer, a nil renderer or a missing template falls
+// back to the built-in message.
+func NewNotificationService(notifiers []notifier.Notifier, recipients RecipientMatcher, renderer MessageRenderer, retry RetryConfig, logger *zap.Logger) NotificationService {
+	retry.Attempts = max(retry.Attempts, 1)
 	return &notificationService{
-		logger: logger.Named("NotificationService"),
+		notifiers:  notifiers,
+		recipients: recipients,
+		renderer:   renderer,
+		retry:      retry,
+		logger:     logger.Named("NotificationService"),
 	}
 }
 
-func (s *notificationService) HandleProductEvent(ctx context.Context, pEvent *models.ProductEvent) error {
+// HandleProductEvent notifies every channel and matching recipient. A failed
+// notification is retried on its own and dropped after the last attempt, so
+// the event doesn't fail and the broker doesn't send it to the channels that
+// succeeded again. It only fails when ctx ends before every notification was
+// tried.
+func (s *notificationService) HandleProductEvent(ctx context.Context, pEvent *productevent.Event) error {
+	ctx, span := tracer.Start(ctx, "NotificationService.HandleProductEvent", trace.WithAttributes(
+		attribute.String("event.id", pEvent.ID),
+		attribute.String("event.type", string(pEvent.Type)),
+		attribute.String("tenant.id", pEvent.TenantID),
+		attribute.String("product.id", pEvent.Product.ID),
+	))
+	defer span.End()
 
-	switch pEvent.EventType {
-	case models.ProductCreated:
-		s.logger.Info("PRODUCT CREATED",
-			zap.String("name", pEvent.Product.Name),
-			zap.String("id", pEvent.Product.ID),
-			zap.Int("price", pEvent.Product.Price),
-			zap.Time("created_at", pEvent.Product.CreatedAt),
-		)
+	logger := loggerPkg.WithContext(ctx, s.logger).With(zap.String("event_id", pEvent.ID))
 
-	case models.ProductDeleted:
-		s.logger.Info("PRODUCT DELETED",
-			zap.String("name", pEvent.Product.Name),
-			zap.String("id", pEvent.Product.ID),
-			zap.Time("created_at", pEvent.Product.CreatedAt),
+	msg, ok := defaultMessage(pEvent)
+	if !ok {
+		logger.Warn("UNKNOWN EVENT TYPE",
+			zap.String("tenant_id", pEvent.TenantID),
+			zap.String("event_type", string(pEvent.Type)),
 		)
+		return nil
+	}
 
-	default:
-		s.logger.Warn("UNKNOWN EVENT TYPE",
-			zap.String("event_type", string(pEvent.EventType)),
-		)
+	notifiers := s.notifiers
+	if s.recipients != nil {
+		notifiers = append(slices.Clip(notifiers), s.recipients.Match(pEvent)...)
 	}
 
+	var failed []string
+	for _, n := range notifiers {
+		if err := s.notifyWithRetry(ctx, logger, n, s.message(logger, pEvent, n.Name(), msg)); err != nil {
+			if ctx.Err() != nil {
+				span.RecordError(ctx.Err())
+				span.SetStatus(codes.Error, "notification interrupted")
+				return ctx.Err()
+			}
+			logger.Error("Dropping notification after the last attempt:", zap.Error(err), zap.String("channel", n.Name()))
+			failed = append(failed, n.Name())
+			continue
+		}
+		logger.Info("Notification sent", zap.String("channel", n.Name()), zap.String("event_type", string(pEvent.Type)))
+	}
+
+	if len(failed) > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("notification failed: %v", failed))
+	}
 	return nil
 }
+
+// notifyWithRetry sends msg over n, retrying with a doubling delay until it
+// succeeds, the attempts are used up or ctx ends, and returns the last error.
+func (s *notificationService) notifyWithRetry(ctx context.Context, logger *zap.Logger, n notifier.Notifier, msg *notifier.Message) error {
+	delay := s.retry.Delay
+	for attempt := 1; ; attempt++ {
+		err := s.notify(ctx, n, msg)
+		if err == nil || attempt >= s.retry.Attempts {
+			return err
+		}
+
+		logger.Warn("Failed to send notification:", zap.Error(err), zap.String("channel", n.Name()),
+			zap.Int("attempt", attempt), zap.Duration("retry_in", delay))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (s *notificationService) notify(ctx context.Context, n notifier.Notifier, msg *notifier.Message) error {
+	ctx, span := tracer.Start(ctx, "Notifier.Notify", trace.WithAttributes(attribute.String("notifier.channel", n.Name())))
+	defer span.End()
+
+	err := n.Notify(ctx, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// message renders the event for channel, falling back to the built-in message.
+// A template that fails to render is logged rather than failing the event, a
+// retry would fail the same way.
+func (s *notificationService) message(logger *zap.Logger, event *productevent.Event, channel string, fallback *notifier.Message) *notifier.Message {
+	if s.renderer == nil {
+		return fallback
+	}
+
+	msg, ok, err := s.renderer.Render(event, channel)
+	if err != nil {
+		logger.Error("Failed to render notification template:", zap.Error(err), zap.String("channel", channel))
+		return fallback
+	}
+	if !ok {
+		return fallback
+	}
+	return msg
+}
+
+// defaultMessage describes the event in plain words, false for unknown event types.
+func defaultMessage(event *productevent.Event) (*notifier.Message, bool) {
+	var action string
+	switch event.Type {
+	case productevent.Created:
+		action = "created"
+	case productevent.Deleted:
+		action = "deleted"
+	default:
+		return nil, false
+	}
+
+	p := event.Product
+	text := fmt.Sprintf("Product %q (%s) was %s in tenant %s.\nPrice: %d.%02d", p.Name, p.ID, action, event.TenantID, p.Price/100, p.Price%100)
+	if event.Actor != "" {
+		text += "\nBy: " + event.Actor
+	}
+
+	return &notifier.Message{
+		Subject: fmt.Sprintf("Product %s: %s", action, p.Name),
+		Text:    text,
+		Event:   event,
+	}, true
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"contracts/productevent"
+	"errors"
+	"notifications/internal/notifier"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockNotifier struct {
+	mock.Mock
+	name string
+}
+
+func (m *MockNotifier) Name() string {
+	return m.name
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, msg *notifier.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+type MockMessageRenderer struct {
+	mock.Mock
+}
+
+func (m *MockMessageRenderer) Render(event *productevent.Event, channel string) (*notifier.Message, bool, error) {
+	args := m.Called(event, channel)
+	msg, _ := args.Get(0).(*notifier.Message)
+	return msg, args.Bool(1), args.Error(2)
+}
+
+type MockRecipientMatcher struct {
+	mock.Mock
+}
+
+func (m *MockRecipientMatcher) Match(event *productevent.Event) []notifier.Notifier {
+	args := m.Called(event)
+	notifiers, _ := args.Get(0).([]notifier.Notifier)
+	return notifiers
+}
+
+func TestNotificationServiceHandleProductEvent(t *testing.T) {
+	ctx := context.Background()
+	event := productevent.New(productevent.Created, productevent.Product{ID: "p1", Name: "Test Product", Price: 12345}, productevent.Metadata{TenantID: "acme", Actor: "alice"})
+
+	t.Run("Notifies Every Channel", func(t *testing.T) {
+		email := &MockNotifier{name: "email"}
+		chat := &MockNotifier{name: "slack"}
+		service := NewNotificationService([]notifier.Notifier{email, chat}, nil, nil, RetryConfig{}, zap.NewNop())
+
+		isMessage := mock.MatchedBy(func(msg *notifier.Message) bool {
+			return msg.Subject == "Product created: Test Product" &&
+				msg.Text == "Product \"Test Product\" (p1) was created in tenant acme.\nPrice: 123.45\nBy: alice" &&
+				msg.Event == event
+		})
+		email.On("Notify", mock.Anything, isMessage).Return(nil).Once()
+		chat.On("Notify", mock.Anything, isMessage).Return(nil).Once()
+
+		assert.NoError(t, service.HandleProductEvent(ctx, event))
+		email.AssertExpectations(t)
+		chat.AssertExpectations(t)
+	})
+
+	t.Run("Retries Failed Channel", func(t *testing.T) {
+		email := &MockNotifier{name: "email"}
+		chat := &MockNotifier{name: "slack"}
+		service := NewNotificationService([]notifier.Notifier{email, chat}, nil, nil, RetryConfig{Attempts: 3, Delay: time.Millisecond}, zap.NewNop())
+
+		email.On("Notify", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Twice()
+		email.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
+		chat.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.HandleProductEvent(ctx, event))
+		email.AssertExpectations(t)
+		chat.AssertExpectations(t)
+	})
+
+	t.Run("Drops Failed Channel", func(t *testing.T) {
+		email := &MockNotifier{name: "email"}
+		chat := &MockNotifier{name: "slack"}
+		service := NewNotificationService([]notifier.Notifier{email, chat}, nil, nil, RetryConfig{Attempts: 2, Delay: time.Millisecond}, zap.NewNop())
+
+		email.On("Notify", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Twice()
+		chat.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.HandleProductEvent(ctx, event), "A failed channel should not fail the event, a redelivery would notify the others again")
+		email.AssertExpectations(t)
+		chat.AssertExpectations(t)
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		email := &MockNotifier{name: "email"}
+		chat := &MockNotifier{name: "slack"}
+		service := NewNotificationService([]notifier.Notifier{email, chat}, nil, nil, RetryConfig{Attempts: 3, Delay: time.Hour}, zap.NewNop())
+
+		ctx, cancel := context.WithCancel(ctx)
+		email.On("Notify", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Run(func(mock.Arguments) {
+			cancel()
+		}).Once()
+
+		assert.ErrorIs(t, service.HandleProductEvent(ctx, event), context.Canceled)
+		email.AssertExpectations(t)
+		chat.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown Event Type", func(t *testing.T) {
+		email := &MockNotifier{name: "email"}
+		service := NewNotificationService([]notifier.Notifier{email}, nil, nil, RetryConfig{}, zap.NewNop())
+
+		unknown := *event
+		unknown.Type = "product_renamed"
+
+		assert.NoError(t, service.HandleProductEvent(ctx, &unknown))
+		email.AssertNotCalled(t, "Notify")
+	})
+
+	t.Run("Rendered Templates", func(t *testing.T) {
+		email := &MockNotifier{name: "email"}
+		chat := &MockNotifier{name: "slack"}
+		http := &MockNotifier{name: "http"}
+		renderer := &MockMessageRenderer{}
+		service := NewNotificationService([]notifier.Notifier{email, chat, http}, nil, renderer, RetryConfig{}, zap.NewNop())
+
+		rendered := &notifier.Message{Subject: "New: Test Product", Text: "rendered", Event: event}
+		renderer.On("Render", event, "email").Return(rendered, true, nil).Once()
+		renderer.On("Render", event, "slack").Return(nil, false, nil).Once()
+		renderer.On("Render", event, "http").Return(nil, true, errors.New("template: missing key")).Once()
+
+		isDefault := mock.MatchedBy(func(msg *notifier.Message) bool {
+			return msg.Subject == "Product created: Test Product"
+		})
+		email.On("Notify", mock.Anything, rendered).Return(nil).Once()
+		chat.On("Notify", mock.Anything, isDefault).Return(nil).Once()
+		http.On("Notify", mock.Anything, isDefault).Return(nil).Once()
+
+		assert.NoError(t, service.HandleProductEvent(ctx, event))
+		renderer.AssertExpectations(t)
+		email.AssertExpectations(t)
+		chat.AssertExpectations(t)
+		http.AssertExpectations(t)
+	})
+
+	t.Run("Matched Recipients", func(t *testing.T) {
+		email := &MockNotifier{name: "email"}
+		subscriber := &MockNotifier{name: "slack"}
+		recipients := &MockRecipientMatcher{}
+		service := NewNotificationService([]notifier.Notifier{email}, recipients, nil, RetryConfig{}, zap.NewNop())
+
+		recipients.On("Match", event).Return([]notifier.Notifier{subscriber}).Once()
+		email.On("Notify", mock.Anything, mock.Anything).Return(nil).Once()
+		subscriber.On("Notify", mock.Anything, mock.Anything).Return(errors.New("webhook returned 404")).Once()
+
+		assert.NoError(t, service.HandleProductEvent(ctx, event))
+		recipients.AssertExpectations(t)
+		email.AssertExpectations(t)
+		subscriber.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"contracts/productevent"
+	"fmt"
+	"notifications/internal/apperrors"
+	"notifications/internal/models"
+	"notifications/internal/notifier"
+	"notifications/internal/rules"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+type SubscribersRepository interface {
+	Create(ctx context.Context, sub *models.Subscriber) (*models.Subscriber, error)
+	Get(ctx context.Context, id string) (*models.Subscriber, error)
+	List(ctx context.Context) ([]models.Subscriber, error)
+	ListActive(ctx context.Context) ([]models.Subscriber, error)
+	Update(ctx context.Context, id string, update *models.UpdateSubscriberDTO) (*models.Subscriber, error)
+	Delete(ctx context.Context, id string) (*models.Subscriber, error)
+}
+
+// NotifierFactory creates the notifier that sends to address over channel,
+// failing when the address is invalid or the channel isn't available.
+type NotifierFactory func(channel, address string) (notifier.Notifier, error)
+
+// SubscribersService manages the subscribers and matches product events to
+// them. The active subscribers are kept in memory with their compiled rules and
+// notifiers, and reloaded after every change made through the service.
+type SubscribersService struct {
+	repo        SubscribersRepository
+	newNotifier NotifierFactory
+	logger      *zap.Logger
+
+	reloadMu sync.Mutex
+	active   atomic.Pointer[[]subscriber]
+}
+
+type subscriber struct {
+	id        string
+	tenantID  string
+	rule      *rules.Rule
+	notifiers []notifier.Notifier
+}
+
+// NewSubscribersService creates the service and loads the active subscribers.
+func NewSubscribersService(ctx context.Context, repo SubscribersRepository, newNotifier NotifierFactory, logger *zap.Logger) (*SubscribersService, error) {
+	s := &SubscribersService{
+		repo:        repo,
+		newNotifier: newNotifier,
+		logger:      logger.Named("SubscribersService"),
+	}
+	if err := s.reload(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load subscribers: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SubscribersService) Create(ctx context.Context, createDTO *models.CreateSubscriberDTO) (*models.Subscriber, error) {
+	if err := s.validate(createDTO.Rule, createDTO.Addresses); err != nil {
+		return nil, err
+	}
+
+	active := true
+	if createDTO.Active != nil {
+		active = *createDTO.Active
+	}
+
+	sub, err := s.repo.Create(ctx, &models.Subscriber{
+		Name:      createDTO.Name,
+		TenantID:  createDTO.TenantID,
+		Rule:      createDTO.Rule,
+		Addresses: createDTO.Addresses,
+		Active:    active,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.reloadAfterChange(ctx)
+	return sub, nil
+}
+
+func (s *SubscribersService) Get(ctx context.Context, id string) (*models.Subscriber, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *SubscribersService) List(ctx context.Context) ([]models.Subscriber, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *SubscribersService) Update(ctx context.Context, id string, updateDTO *models.UpdateSubscriberDTO) (*models.Subscriber, error) {
+	if updateDTO.Rule != nil {
+		if err := s.validate(*updateDTO.Rule, nil); err != nil {
+			return nil, err
+		}
+	}
+	if updateDTO.Addresses != nil {
+		if err := s.validate("", *updateDTO.Addresses); err != nil {
+			return nil, err
+		}
+	}
+
+	sub, err := s.repo.Update(ctx, id, updateDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	s.reloadAfterChange(ctx)
+	return sub, nil
+}
+
+func (s *SubscribersService) Delete(ctx context.Context, id string) (*models.Subscriber, error) {
+	sub, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.reloadAfterChange(ctx)
+	return sub, nil
+}
+
+// Match returns the notifiers of the active subscribers whose tenant and rule
+// match event. A rule that fails on the event is logged and doesn't match.
+func (s *SubscribersService) Match(event *productevent.Event) []notifier.Notifier {
+	var notifiers []notifier.Notifier
+	for _, sub := range *s.active.Load() {
+		if sub.tenantID != "" && sub.tenantID != event.TenantID {
+			continue
+		}
+
+		matched, err := sub.rule.Match(event)
+		if err != nil {
+			s.logger.Warn("Failed to evaluate subscriber rule:", zap.Error(err), zap.String("subscriber_id", sub.id), zap.String("event_id", event.ID))
+			continue
+		}
+		if matched {
+			notifiers = append(notifiers, sub.notifiers...)
+		}
+	}
+	return notifiers
+}
+
+func (s *SubscribersService) validate(rule string, addresses []models.SubscriberAddress) error {
+	if _, err := rules.Compile(rule); err != nil {
+		return fmt.Errorf("%w: rule: %v", apperrors.ErrSubscriberInvalid, err)
+	}
+	for _, addr := range addresses {
+		if _, err := s.newNotifier(addr.Channel, addr.Address); err != nil {
+			return fmt.Errorf("%w: %s address %q: %v", apperrors.ErrSubscriberInvalid, addr.Channel, addr.Address, err)
+		}
+	}
+	return nil
+}
+
+// reloadAfterChange reloads the active subscribers after a change that is
+// already stored, so a failure only delays the change taking effect until the
+// next one.
+func (s *SubscribersService) reloadAfterChange(ctx context.Context) {
+	if err := s.reload(context.WithoutCancel(ctx)); err != nil {
+		s.logger.Error("Failed to reload subscribers:", zap.Error(err))
+	}
+}
+
+// reload replaces the active subscribers with the stored ones. A subscriber
+// that is no longer valid, e.g. because its channel was unconfigured, is
+// skipped with a warning.
+func (s *SubscribersService) reload(ctx context.Context) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	stored, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	active := make([]subscriber, 0, len(stored))
+	for _, sub := range stored {
+		compiled, err := s.compile(sub)
+		if err != nil {
+			s.logger.Warn("Skipping invalid subscriber:", zap.Error(err), zap.String("subscriber_id", sub.ID))
+			continue
+		}
+		active = append(active, compiled)
+	}
+
+	s.active.Store(&active)
+	return nil
+}
+
+func (s *SubscribersService) compile(sub models.Subscriber) (subscriber, error) {
+	rule, err := rules.Compile(sub.Rule)
+	if err != nil {
+		return subscriber{}, err
+	}
+
+	notifiers := make([]notifier.Notifier, 0, len(sub.Addresses))
+	for _, addr := range sub.Addresses {
+		n, err := s.newNotifier(addr.Channel, addr.Address)
+		if err != nil {
+			return subscriber{}, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	return subscriber{id: sub.ID, tenantID: sub.TenantID, rule: rule, notifiers: notifiers}, nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"contracts/productevent"
+	"errors"
+	"notifications/internal/apperrors"
+	"notifications/internal/models"
+	"notifications/internal/notifier"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockSubscribersRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscribersRepository) Create(ctx context.Context, sub *models.Subscriber) (*models.Subscriber, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscribersRepository) Get(ctx context.Context, id string) (*models.Subscriber, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscribersRepository) List(ctx context.Context) ([]models.Subscriber, error) {
+	args := m.Called(ctx)
+	subs, _ := args.Get(0).([]models.Subscriber)
+	return subs, args.Error(1)
+}
+
+func (m *MockSubscribersRepository) ListActive(ctx context.Context) ([]models.Subscriber, error) {
+	args := m.Called(ctx)
+	subs, _ := args.Get(0).([]models.Subscriber)
+	return subs, args.Error(1)
+}
+
+func (m *MockSubscribersRepository) Update(ctx context.Context, id string, update *models.UpdateSubscriberDTO) (*models.Subscriber, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscribersRepository) Delete(ctx context.Context, id string) (*models.Subscriber, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+// testNotifierFactory creates notifiers named after the channel that remember
+// their address, and rejects the channel "pager".
+func testNotifierFactory(channel, address string) (notifier.Notifier, error) {
+	if channel == "pager" {
+		return nil, errors.New("unknown channel")
+	}
+	return &MockNotifier{name: channel + ":" + address}, nil
+}
+
+func notifierNames(notifiers []notifier.Notifier) []string {
+	names := []string{}
+	for _, n := range notifiers {
+		names = append(names, n.Name())
+	}
+	return names
+}
+
+func TestSubscribersServiceMatch(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockSubscribersRepository{}
+	repo.On("ListActive", mock.Anything).Return([]models.Subscriber{
+		{ID: "all", Addresses: []models.SubscriberAddress{{Channel: "email", Address: "all@example.com"}}},
+		{ID: "expensive", Rule: `event_type == "product_created" && product.price > 10000`, Addresses: []models.SubscriberAddress{
+			{Channel: "email", Address: "sales@example.com"},
+			{Channel: "slack", Address: "https://hooks.example.com/sales"},
+		}},
+		{ID: "acme", TenantID: "acme", Addresses: []models.SubscriberAddress{{Channel: "http", Address: "https://acme.example.com/hook"}}},
+		{ID: "broken rule", Rule: `product.color == "red"`, Addresses: []models.SubscriberAddress{{Channel: "email", Address: "broken@example.com"}}},
+		{ID: "unavailable channel", Addresses: []models.SubscriberAddress{{Channel: "pager", Address: "123"}}},
+	}, nil).Once()
+
+	s, err := NewSubscribersService(ctx, repo, testNotifierFactory, zap.NewNop())
+	require.NoError(t, err)
+
+	type testCase struct {
+		name      string
+		event     *productevent.Event
+		notifiers []string
+	}
+
+	cases := []testCase{
+		{
+			name:      "Expensive Product",
+			event:     productevent.New(productevent.Created, productevent.Product{Price: 25000}, productevent.Metadata{TenantID: "globex"}),
+			notifiers: []string{"email:all@example.com", "email:sales@example.com", "slack:https://hooks.example.com/sales"},
+		},
+		{
+			name:      "Cheap Product",
+			event:     productevent.New(productevent.Created, productevent.Product{Price: 500}, productevent.Metadata{TenantID: "globex"}),
+			notifiers: []string{"email:all@example.com"},
+		},
+		{
+			name:      "Tenant",
+			event:     productevent.New(productevent.Deleted, productevent.Product{Price: 25000}, productevent.Metadata{TenantID: "acme"}),
+			notifiers: []string{"email:all@example.com", "http:https://acme.example.com/hook"},
+		},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			assert.Equal(t, tCase.notifiers, notifierNames(s.Match(tCase.event)))
+		})
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestSubscribersServiceCreate(t *testing.T) {
+	ctx := context.Background()
+	addresses := []models.SubscriberAddress{{Channel: "email", Address: "sales@example.com"}}
+	event := productevent.New(productevent.Created, productevent.Product{Price: 25000}, productevent.Metadata{TenantID: "acme"})
+
+	t.Run("Reloads Subscribers", func(t *testing.T) {
+		repo := &MockSubscribersRepository{}
+		repo.On("ListActive", mock.Anything).Return([]models.Subscriber{}, nil).Once()
+		s, err := NewSubscribersService(ctx, repo, testNotifierFactory, zap.NewNop())
+		require.NoError(t, err)
+		assert.Empty(t, s.Match(event))
+
+		created := &models.Subscriber{ID: "sales", Rule: `product.price > 10000`, Addresses: addresses, Active: true}
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(sub *models.Subscriber) bool {
+			return sub.Name == "Sales" && sub.Rule == created.Rule && sub.Active
+		})).Return(created, nil).Once()
+		repo.On("ListActive", mock.Anything).Return([]models.Subscriber{*created}, nil).Once()
+
+		sub, err := s.Create(ctx, &models.CreateSubscriberDTO{Name: "Sales", Rule: created.Rule, Addresses: addresses})
+		require.NoError(t, err)
+		assert.Equal(t, created, sub)
+		assert.Equal(t, []string{"email:sales@example.com"}, notifierNames(s.Match(event)))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		type testCase struct {
+			name      string
+			createDTO *models.CreateSubscriberDTO
+		}
+
+		cases := []testCase{
+			{name: "Unknown Field", createDTO: &models.CreateSubscriberDTO{Name: "x", Rule: `product.color == "red"`, Addresses: addresses}},
+			{name: "Not A Boolean", createDTO: &models.CreateSubscriberDTO{Name: "x", Rule: `product.price`, Addresses: addresses}},
+			{name: "Unavailable Channel", createDTO: &models.CreateSubscriberDTO{Name: "x", Addresses: []models.SubscriberAddress{{Channel: "pager", Address: "123"}}}},
+		}
+
+		repo := &MockSubscribersRepository{}
+		repo.On("ListActive", mock.Anything).Return([]models.Subscriber{}, nil).Once()
+		s, err := NewSubscribersService(ctx, repo, testNotifierFactory, zap.NewNop())
+		require.NoError(t, err)
+
+		for _, tCase := range cases {
+			t.Run(tCase.name, func(t *testing.T) {
+				_, err := s.Create(ctx, tCase.createDTO)
+				assert.ErrorIs(t, err, apperrors.ErrSubscriberInvalid)
+			})
+		}
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSubscribersServiceUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockSubscribersRepository{}
+	repo.On("ListActive", mock.Anything).Return([]models.Subscriber{}, nil).Once()
+	s, err := NewSubscribersService(ctx, repo, testNotifierFactory, zap.NewNop())
+	require.NoError(t, err)
+
+	t.Run("Invalid Rule", func(t *testing.T) {
+		rule := `event_type ==`
+		_, err := s.Update(ctx, "sales", &models.UpdateSubscriberDTO{Rule: &rule})
+		assert.ErrorIs(t, err, apperrors.ErrSubscriberInvalid)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		active := false
+		update := &models.UpdateSubscriberDTO{Active: &active}
+		repo.On("Update", mock.Anything, "missing", update).Return(nil, apperrors.ErrSubscriberNotFound).Once()
+
+		_, err := s.Update(ctx, "missing", update)
+		assert.ErrorIs(t, err, apperrors.ErrSubscriberNotFound)
+	})
+
+	repo.AssertExpectations(t)
+}
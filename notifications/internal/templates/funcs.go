@@ -0,0 +1,67 @@
+package templates
+
+import (
+	"contracts/productevent"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// funcs are the helpers available in every template.
+var funcs = map[string]any{
+	"money":      money,
+	"currency":   currency,
+	"date":       date,
+	"datetime":   datetime,
+	"formatTime": formatTime,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+}
+
+// money formats cents with thousands separators and two decimals, e.g. 1234567 as "12,345.67".
+func money(c productevent.Cents) string {
+	sign := ""
+	if c < 0 {
+		sign = "-"
+		c = -c
+	}
+
+	units := strconv.FormatInt(int64(c/100), 10)
+	var grouped strings.Builder
+	for i, digit := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	cents := strconv.FormatInt(int64(c%100), 10)
+	if len(cents) == 1 {
+		cents = "0" + cents
+	}
+	return sign + grouped.String() + "." + cents
+}
+
+// currency prefixes money with a symbol, e.g. currency "$" 1999 is "$19.99".
+func currency(symbol string, c productevent.Cents) string {
+	formatted := money(c)
+	if strings.HasPrefix(formatted, "-") {
+		return "-" + symbol + formatted[1:]
+	}
+	return symbol + formatted
+}
+
+// date formats t as 2006-01-02 in UTC.
+func date(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// datetime formats t as 2006-01-02 15:04 UTC.
+func datetime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
+
+// formatTime formats t in UTC with a Go layout, e.g. formatTime "02 Jan 2006" .Time.
+func formatTime(layout string, t time.Time) string {
+	return t.UTC().Format(layout)
+}
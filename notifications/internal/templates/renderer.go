@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"context"
+	"contracts/productevent"
+	"notifications/internal/notifier"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay collects the burst of file events an editor or a config map
+// update causes into one reload.
+const reloadDelay = 200 * time.Millisecond
+
+// Renderer renders with the templates of a directory and reloads them when
+// the directory changes. A change that doesn't validate is rejected and the
+// previous templates stay in use.
+type Renderer struct {
+	dir    string
+	set    atomic.Pointer[Set]
+	logger *zap.Logger
+}
+
+// NewRenderer loads the templates of dir, failing when they don't validate.
+func NewRenderer(dir string, logger *zap.Logger) (*Renderer, error) {
+	r := &Renderer{
+		dir:    dir,
+		logger: logger.Named("Templates"),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Renderer) Render(event *productevent.Event, channel string) (*notifier.Message, bool, error) {
+	return r.set.Load().Render(event, channel)
+}
+
+// Reload loads the templates again and uses them if they validate.
+func (r *Renderer) Reload() error {
+	set, err := Load(r.dir)
+	if err != nil {
+		return err
+	}
+	r.set.Store(set)
+	return nil
+}
+
+// Watch reloads the templates on changes to the directory until ctx is done.
+func (r *Renderer) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(r.dir); err != nil {
+		return err
+	}
+
+	reload := time.NewTimer(reloadDelay)
+	reload.Stop()
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op != fsnotify.Chmod {
+				reload.Reset(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			r.logger.Error("Template watcher error:", zap.Error(err))
+		case <-reload.C:
+			if err := r.Reload(); err != nil {
+				r.logger.Error("Rejected template change, keeping the previous templates:", zap.Error(err))
+				continue
+			}
+			r.logger.Info("Reloaded templates", zap.String("dir", r.dir))
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package templates renders notification messages from templates in a
+// directory, so their wording can change without a deploy.
+//
+// Template files are named <event type>.<channel>.<part>.tmpl, e.g.
+// product_created.email.html.tmpl. The parts are subject and text, rendered
+// with text/template, and the optional html, rendered with html/template. The
+// channel is a notifier name (email, slack, teams, http) or default, which
+// applies to channels without templates of their own. Templates are executed
+// with the *productevent.Event.
+package templates
+
+import (
+	"bytes"
+	"contracts/productevent"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"notifications/internal/notifier"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// DefaultChannel holds the templates for channels without their own.
+const DefaultChannel = "default"
+
+const (
+	partSubject = "subject"
+	partText    = "text"
+	partHTML    = "html"
+)
+
+// Set is a validated set of templates.
+type Set struct {
+	// messages is keyed by event type and channel.
+	messages map[productevent.Type]map[string]*messageTemplate
+}
+
+type messageTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// Load parses the templates in dir and validates them by rendering each
+// against a sample event.
+func Load(dir string) (*Set, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{messages: map[productevent.Type]map[string]*messageTemplate{}}
+	for _, file := range files {
+		if err := set.parse(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (s *Set) parse(file string) error {
+	parts := strings.Split(strings.TrimSuffix(filepath.Base(file), ".tmpl"), ".")
+	if len(parts) != 3 {
+		return errors.New("expected a name like <event type>.<channel>.<part>.tmpl")
+	}
+	eventType, channel, part := productevent.Type(parts[0]), parts[1], parts[2]
+	if !eventType.Valid() {
+		return fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if s.messages[eventType] == nil {
+		s.messages[eventType] = map[string]*messageTemplate{}
+	}
+	tmpl := s.messages[eventType][channel]
+	if tmpl == nil {
+		tmpl = &messageTemplate{}
+		s.messages[eventType][channel] = tmpl
+	}
+
+	name := filepath.Base(file)
+	switch part {
+	case partSubject:
+		tmpl.subject, err = texttemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+	case partText:
+		tmpl.text, err = texttemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+	case partHTML:
+		tmpl.html, err = htmltemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+	default:
+		return fmt.Errorf("unknown part %q, expected %s, %s or %s", part, partSubject, partText, partHTML)
+	}
+	return err
+}
+
+func (s *Set) validate() error {
+	var errs []error
+	for _, key := range s.keys() {
+		tmpl := s.messages[key.eventType][key.channel]
+		if tmpl.subject == nil || tmpl.text == nil {
+			errs = append(errs, fmt.Errorf("%s.%s: subject and text templates are required", key.eventType, key.channel))
+			continue
+		}
+		if _, err := tmpl.render(SampleEvent(key.eventType)); err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: %w", key.eventType, key.channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type setKey struct {
+	eventType productevent.Type
+	channel   string
+}
+
+// keys returns the event types and channels with templates in a stable order.
+func (s *Set) keys() []setKey {
+	var keys []setKey
+	for eventType, channels := range s.messages {
+		for channel := range channels {
+			keys = append(keys, setKey{eventType, channel})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].eventType != keys[j].eventType {
+			return keys[i].eventType < keys[j].eventType
+		}
+		return keys[i].channel < keys[j].channel
+	})
+	return keys
+}
+
+// Render renders the message of event for channel, false when neither the
+// channel nor the default channel has templates for the event type.
+func (s *Set) Render(event *productevent.Event, channel string) (*notifier.Message, bool, error) {
+	channels := s.messages[event.Type]
+	tmpl, ok := channels[channel]
+	if !ok {
+		tmpl, ok = channels[DefaultChannel]
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	msg, err := tmpl.render(event)
+	return msg, true, err
+}
+
+func (t *messageTemplate) render(event *productevent.Event) (*notifier.Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, event); err != nil {
+		return nil, err
+	}
+	if err := t.text.Execute(&text, event); err != nil {
+		return nil, err
+	}
+	if t.html != nil {
+		if err := t.html.Execute(&html, event); err != nil {
+			return nil, err
+		}
+	}
+
+	return &notifier.Message{
+		// Template files end in a newline, which isn't part of the message.
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimRight(text.String(), "\n"),
+		HTML:    html.String(),
+		Event:   event,
+	}, nil
+}
+
+// SampleEvent is the event templates are validated against.
+func SampleEvent(eventType productevent.Type) *productevent.Event {
+	event := productevent.New(eventType, productevent.Product{
+		ID:          "00000000-0000-0000-0000-000000000001",
+		Name:        "Sample product",
+		Description: "A product to check templates with",
+		Price:       123456,
+		CreatedAt:   time.Date(2025, time.January, 2, 15, 4, 5, 0, time.UTC),
+	}, productevent.Metadata{
+		TenantID:      "default",
+		CorrelationID: "sample-request",
+		Actor:         "sample-user",
+	})
+	event.Time = time.Date(2025, time.January, 2, 15, 4, 5, 0, time.UTC)
+	return event
+}
@@ -0,0 +1,213 @@
+package templates
+
+import (
+	"context"
+	"contracts/productevent"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writeTemplates writes files, keyed by name, to a new directory.
+func writeTemplates(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestLoadShippedTemplates(t *testing.T) {
+	_, err := Load("../../templates")
+	assert.NoError(t, err)
+}
+
+func TestSetRender(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"product_created.default.subject.tmpl": "Created: {{.Product.Name}}\n",
+		"product_created.default.text.tmpl":    "{{.Product.Name}} costs {{currency \"$\" .Product.Price}} since {{date .Product.CreatedAt}}.\n",
+		"product_created.email.subject.tmpl":   "New product {{.Product.Name}}\n",
+		"product_created.email.text.tmpl":      "{{upper .Product.Name}}\n",
+		"product_created.email.html.tmpl":      "<p>{{.Product.Name}}</p>\n",
+	})
+	set, err := Load(dir)
+	require.NoError(t, err)
+
+	event := productevent.New(productevent.Created, productevent.Product{
+		ID:        "p1",
+		Name:      "Tea & <Biscuits>",
+		Price:     1234567,
+		CreatedAt: time.Date(2025, time.March, 4, 23, 0, 0, 0, time.UTC),
+	}, productevent.Metadata{TenantID: "acme"})
+
+	t.Run("Channel Templates", func(t *testing.T) {
+		msg, ok, err := set.Render(event, "email")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "New product Tea & <Biscuits>", msg.Subject)
+		assert.Equal(t, "TEA & <BISCUITS>", msg.Text)
+		assert.Equal(t, "<p>Tea &amp; &lt;Biscuits&gt;</p>\n", msg.HTML)
+		assert.Same(t, event, msg.Event)
+	})
+
+	t.Run("Default Channel", func(t *testing.T) {
+		msg, ok, err := set.Render(event, "slack")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "Created: Tea & <Biscuits>", msg.Subject)
+		assert.Equal(t, "Tea & <Biscuits> costs $12,345.67 since 2025-03-04.", msg.Text)
+		assert.Empty(t, msg.HTML)
+	})
+
+	t.Run("No Templates For Event Type", func(t *testing.T) {
+		deleted := *event
+		deleted.Type = productevent.Deleted
+
+		_, ok, err := set.Render(&deleted, "email")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestLoadInvalidTemplates(t *testing.T) {
+	type testCase struct {
+		name  string
+		files map[string]string
+		err   string
+	}
+
+	cases := []testCase{
+		{
+			name:  "Bad File Name",
+			files: map[string]string{"product_created.subject.tmpl": "x"},
+			err:   "expected a name like",
+		},
+		{
+			name:  "Unknown Event Type",
+			files: map[string]string{"product_renamed.default.subject.tmpl": "x"},
+			err:   `unknown event type "product_renamed"`,
+		},
+		{
+			name:  "Unknown Part",
+			files: map[string]string{"product_created.default.body.tmpl": "x"},
+			err:   `unknown part "body"`,
+		},
+		{
+			name:  "Syntax Error",
+			files: map[string]string{"product_created.default.subject.tmpl": "{{.Product.Name"},
+			err:   "product_created.default.subject.tmpl",
+		},
+		{
+			name:  "Missing Text",
+			files: map[string]string{"product_created.default.subject.tmpl": "x"},
+			err:   "product_created.default: subject and text templates are required",
+		},
+		{
+			name: "Unknown Field",
+			files: map[string]string{
+				"product_created.default.subject.tmpl": "{{.Product.Title}}",
+				"product_created.default.text.tmpl":    "x",
+			},
+			err: "can't evaluate field Title",
+		},
+		{
+			name: "Wrong Helper Argument",
+			files: map[string]string{
+				"product_created.default.subject.tmpl": "x",
+				"product_created.default.text.tmpl":    "{{money .Product.Name}}",
+			},
+			err: "product_created.default",
+		},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, err := Load(writeTemplates(t, tCase.files))
+			assert.ErrorContains(t, err, tCase.err)
+		})
+	}
+}
+
+func TestMoney(t *testing.T) {
+	type testCase struct {
+		cents    productevent.Cents
+		money    string
+		currency string
+	}
+
+	cases := []testCase{
+		{cents: 0, money: "0.00", currency: "€0.00"},
+		{cents: 5, money: "0.05", currency: "€0.05"},
+		{cents: 1999, money: "19.99", currency: "€19.99"},
+		{cents: 100000, money: "1,000.00", currency: "€1,000.00"},
+		{cents: 123456789, money: "1,234,567.89", currency: "€1,234,567.89"},
+		{cents: -25050, money: "-250.50", currency: "-€250.50"},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.money, func(t *testing.T) {
+			assert.Equal(t, tCase.money, money(tCase.cents))
+			assert.Equal(t, tCase.currency, currency("€", tCase.cents))
+		})
+	}
+}
+
+func TestDates(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	tm := time.Date(2025, time.December, 31, 23, 30, 0, 0, berlin)
+
+	assert.Equal(t, "2025-12-31", date(tm))
+	assert.Equal(t, "2025-12-31 22:30 UTC", datetime(tm))
+	assert.Equal(t, "31 Dec 2025", formatTime("02 Jan 2006", tm))
+}
+
+func TestRendererWatch(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"product_created.default.subject.tmpl": "v1",
+		"product_created.default.text.tmpl":    "text",
+	})
+	renderer, err := NewRenderer(dir, zap.NewNop())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watching := make(chan error, 1)
+	go func() { watching <- renderer.Watch(ctx) }()
+
+	event := SampleEvent(productevent.Created)
+	subject := func() string {
+		msg, _, err := renderer.Render(event, "email")
+		if err != nil {
+			return err.Error()
+		}
+		return msg.Subject
+	}
+	write := func(content string) error {
+		return os.WriteFile(filepath.Join(dir, "product_created.default.subject.tmpl"), []byte(content), 0o644)
+	}
+
+	// Watch adds the directory asynchronously, keep writing until a reload is
+	// seen. Each write postpones the reload, so they are further apart than its delay.
+	require.Eventually(t, func() bool {
+		return write("v2") == nil && subject() == "v2"
+	}, 5*time.Second, 3*reloadDelay)
+
+	t.Run("Invalid Change Keeps Templates", func(t *testing.T) {
+		require.NoError(t, write("{{.Nope}}"))
+		time.Sleep(4 * reloadDelay)
+		assert.Equal(t, "v2", subject())
+	})
+
+	t.Run("Fixed Change Is Loaded", func(t *testing.T) {
+		require.NoError(t, write("v3 {{.Product.Name}}"))
+		assert.Eventually(t, func() bool { return subject() == "v3 Sample product" }, 5*time.Second, 50*time.Millisecond)
+	})
+
+	cancel()
+	assert.NoError(t, <-watching)
+}
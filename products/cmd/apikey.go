@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"products/internal/config"
+	"products/internal/models"
+	"products/internal/repository/pg"
+	"products/internal/services"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const apiKeyUsage = `Usage:
+  main apikey create -label <label> -scopes read,write,admin [-tenant <id>] [-ttl 720h]
+  main apikey list
+  main apikey revoke <id>
+`
+
+// runAPIKeyCommand manages API keys from the command line and returns the process exit code.
+func runAPIKeyCommand(cfg *config.Config, args []string, logger *zap.Logger) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, apiKeyUsage)
+		return 2
+	}
+
+	db, err := connectDB(cfg.DB)
+	if err != nil {
+		logger.Error("Failed to connect to database", zap.Error(err))
+		return 1
+	}
+	defer db.Close()
+
+	apiKeysService := services.NewAPIKeysService(pg.NewAPIKeysRepository(db), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch args[0] {
+	case "create":
+		err = createAPIKey(ctx, apiKeysService, args[1:])
+	case "list":
+		err = listAPIKeys(ctx, apiKeysService)
+	case "revoke":
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, apiKeyUsage)
+			return 2
+		}
+		err = revokeAPIKey(ctx, apiKeysService, args[1])
+	default:
+		fmt.Fprint(os.Stderr, apiKeyUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "apikey %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func createAPIKey(ctx context.Context, apiKeysService *services.APIKeysService, args []string) error {
+	fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	label := fs.String("label", "", "human readable key label")
+	scopes := fs.String("scopes", string(models.ScopeRead), "comma separated scopes: read, write, admin")
+	tenantID := fs.String("tenant", "", "bind the key to a tenant, empty means any tenant")
+	ttl := fs.Duration("ttl", 0, "key lifetime, 0 means the key never expires")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	createDTO := &models.CreateAPIKeyDTO{Label: *label, TenantID: *tenantID}
+	for _, s := range strings.Split(*scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			createDTO.Scopes = append(createDTO.Scopes, models.APIKeyScope(s))
+		}
+	}
+	if *ttl > 0 {
+		expiresAt := time.Now().Add(*ttl)
+		createDTO.ExpiresAt = &expiresAt
+	}
+
+	key, rawKey, err := apiKeysService.Create(ctx, createDTO)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id:     %s\n", key.ID)
+	fmt.Printf("label:  %s\n", key.Label)
+	fmt.Printf("scopes: %s\n", formatScopes(key.Scopes))
+	fmt.Printf("tenant: %s\n", formatTenant(key.TenantID))
+	fmt.Printf("key:    %s\n", rawKey)
+	fmt.Println("Store the key now, it can't be shown again.")
+	return nil
+}
+
+func listAPIKeys(ctx context.Context, apiKeysService *services.APIKeysService) error {
+	keys, err := apiKeysService.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLABEL\tSCOPES\tTENANT\tEXPIRES\tREVOKED\tCREATED")
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			k.ID, k.Label, formatScopes(k.Scopes), formatTenant(k.TenantID), formatTime(k.ExpiresAt), formatTime(k.RevokedAt), k.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func revokeAPIKey(ctx context.Context, apiKeysService *services.APIKeysService, id string) error {
+	key, err := apiKeysService.Revoke(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("revoked %s (%s)\n", key.ID, key.Label)
+	return nil
+}
+
+func formatScopes(scopes []models.APIKeyScope) string {
+	s := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		s = append(s, string(scope))
+	}
+	return strings.Join(s, ",")
+}
+
+func formatTenant(tenantID string) string {
+	if tenantID == "" {
+		return "*"
+	}
+	return tenantID
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -6,16 +6,28 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"products/internal/auth"
 	"products/internal/config"
 	"products/internal/handlers"
 	loggerPkg "products/internal/logger"
 	"products/internal/messaging"
+	"products/internal/metrics"
+	middleware "products/internal/middlewares"
+	"products/internal/models"
+	"products/internal/outbox"
+	"products/internal/ratelimit"
 	"products/internal/repository/pg"
+	"products/internal/serde"
 	"products/internal/services"
+	"products/internal/stream"
+	"products/internal/tracing"
+	"products/internal/webhooks"
 	"syscall"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.uber.org/zap"
 
 	_ "github.com/lib/pq"
@@ -27,10 +39,39 @@ func main() {
 	defer logger.Sync()
 	cfg := config.Load()
 
-	broker, err := messaging.NewKafkaBroker(messaging.Config{
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		os.Exit(runAPIKeyCommand(cfg, os.Args[2:], logger))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "schema-registry" {
+		os.Exit(runSchemaRegistryCommand(os.Args[2:], logger))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "products",
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.FilePath,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
+	broker, err := messaging.NewBroker(messaging.Config{
+		Type:         cfg.MessageBroker.Type,
 		Endpoint:     cfg.MessageBroker.Endpoint,
 		BaseClientID: cfg.MessageBroker.ClientID,
 		Topic:        cfg.MessageBroker.Topic,
+		Stream:       cfg.MessageBroker.Stream,
+
+		DeliveryMode:       cfg.MessageBroker.DeliveryMode,
+		StatisticsInterval: cfg.MessageBroker.StatisticsInterval,
 	}, logger)
 
 	if err != nil {
@@ -38,21 +79,121 @@ func main() {
 	}
 	defer broker.Close()
 
-	db, err := sqlx.Connect("postgres", fmt.Sprintf("user=%s dbname=%s password=%s host=%s port=%s sslmode=%s", cfg.DB.User, cfg.DB.DBName, cfg.DB.Password, cfg.DB.Host, cfg.DB.Port, cfg.DB.SSLMode))
+	db, err := connectDB(cfg.DB)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
 	defer db.Close()
+	metrics.RegisterDBStats(db.DB, cfg.DB.DBName)
 
 	ProductsRepository := pg.NewProductsRepository(db)
-	productsService := services.NewProductsService(ProductsRepository, broker, logger)
+	outboxRepository := pg.NewOutboxRepository(db)
+	txManager := pg.NewTxManager(db)
+	serializer, err := newEventSerializer(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize event serializer", zap.Error(err))
+	}
+	webhooksRepository := pg.NewWebhooksRepository(db)
+	webhooksService := services.NewWebhooksService(webhooksRepository, logger)
+	// Only clients connected to the replica that made a change get its events.
+	streamHub := stream.NewHub(stream.Config{
+		HistorySize: cfg.Stream.HistorySize,
+		BufferSize:  cfg.Stream.BufferSize,
+		MaxClients:  cfg.Stream.MaxClients,
+	}, logger)
+	productsService := services.NewProductsService(ProductsRepository, outboxRepository, webhooksService, streamHub, txManager, serializer, logger)
 	productsHandler := handlers.NewProductsHandler(productsService, logger)
+	streamHandler := handlers.NewStreamHandler(streamHub, cfg.Stream.HeartbeatInterval, cfg.Stream.WriteTimeout, logger)
+	wsHandler := handlers.NewWebSocketHandler(streamHub, handlers.WebSocketConfig{
+		MaxConnections:   cfg.WebSocket.MaxConnections,
+		MaxSubscriptions: cfg.WebSocket.MaxSubscriptions,
+		PingInterval:     cfg.WebSocket.PingInterval,
+		PongTimeout:      cfg.WebSocket.PongTimeout,
+		WriteTimeout:     cfg.WebSocket.WriteTimeout,
+		AllowedOrigins:   cfg.WebSocket.AllowedOrigins,
+	}, logger)
+	webhooksHandler := handlers.NewWebhooksHandler(webhooksService, logger)
+	apiKeysService := services.NewAPIKeysService(pg.NewAPIKeysRepository(db), logger)
+
+	appCtx, stopApp := context.WithCancel(context.Background())
+	defer stopApp()
 
-	router := handlers.SetupRoutes(productsHandler, logger)
+	var tokens middleware.TokenVerifier
+	if cfg.JWT.JWKSSource != "" {
+		verifier, err := newJWTVerifier(appCtx, cfg.JWT, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize jwt verifier", zap.Error(err))
+		}
+		tokens = verifier
+	}
+
+	relay := outbox.NewRelay(outboxRepository, broker, outbox.Config{
+		PollInterval:    cfg.Outbox.PollInterval,
+		BatchSize:       cfg.Outbox.BatchSize,
+		PublishTimeout:  cfg.Outbox.PublishTimeout,
+		MinBackoff:      cfg.Outbox.MinBackoff,
+		MaxBackoff:      cfg.Outbox.MaxBackoff,
+		Retention:       cfg.Outbox.Retention,
+		CleanupInterval: cfg.Outbox.CleanupInterval,
+		// Only Kafka returns from Send before the broker acknowledged the message.
+		AsyncDelivery: cfg.MessageBroker.Type == messaging.TypeKafka && cfg.MessageBroker.DeliveryMode != messaging.DeliverySync,
+	}, logger)
+	// Delivery reports come in both modes, in async mode the relay marks the outbox from them.
+	if reporter, ok := broker.(messaging.DeliveryReporter); ok {
+		reporter.SetDeliveryHandler(func(report models.DeliveryReport) {
+			productsService.HandleDeliveryReport(report)
+			relay.HandleDeliveryReport(report)
+		})
+	}
+	// Only the elected replica relays, woken up by outbox insert notifications.
+	relayElector := pg.NewLeaderElector(db, "outbox_relay", pg.OutboxRelayLock, cfg.Outbox.LeaderCheckInterval, logger)
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		relayElector.Run(appCtx, func(ctx context.Context) {
+			relay.Run(ctx, pg.ListenOutbox(ctx, dsn(cfg.DB), logger))
+		})
+	}()
+
+	// Deliveries are claimed with a lease, every replica dispatches.
+	dispatcher := webhooks.NewDispatcher(webhooksRepository, webhooks.Config{
+		PollInterval:    cfg.Webhooks.PollInterval,
+		BatchSize:       cfg.Webhooks.BatchSize,
+		Timeout:         cfg.Webhooks.Timeout,
+		MaxAttempts:     cfg.Webhooks.MaxAttempts,
+		MinBackoff:      cfg.Webhooks.MinBackoff,
+		MaxBackoff:      cfg.Webhooks.MaxBackoff,
+		DisableAfter:    cfg.Webhooks.DisableAfter,
+		Retention:       cfg.Webhooks.Retention,
+		CleanupInterval: cfg.Webhooks.CleanupInterval,
+	}, logger)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(appCtx)
+	}()
+
+	rateLimitStore := ratelimit.NewMemoryStore()
+	go rateLimitStore.Run(appCtx, 10*time.Minute)
+
+	healthHandler := handlers.NewHealthHandler(map[string]handlers.HealthCheck{
+		"postgres":             db.PingContext,
+		cfg.MessageBroker.Type: broker.Ping,
+	}, 2*time.Second, logger)
+
+	router := handlers.SetupRoutes(productsHandler, streamHandler, wsHandler, webhooksHandler, healthHandler, tokens, apiKeysService, handlers.RateLimits{
+		Store: rateLimitStore,
+		IP:    ratelimit.Limit{Rate: cfg.RateLimit.IPRate, Burst: cfg.RateLimit.IPBurst},
+		Read:  ratelimit.Limit{Rate: cfg.RateLimit.ReadRate, Burst: cfg.RateLimit.ReadBurst},
+		Write: ratelimit.Limit{Rate: cfg.RateLimit.WriteRate, Burst: cfg.RateLimit.WriteBurst},
+	}, logger)
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.HTTP.Port),
 		Handler: router,
 	}
+	// Open streams would otherwise hold up Shutdown until its timeout, WebSockets
+	// aren't tracked by it at all.
+	server.RegisterOnShutdown(streamHub.Close)
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -64,7 +205,10 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	<-quit
-	logger.Info("Shutting down server...")
+	logger.Info("Shutting down server...", zap.Duration("drain_delay", cfg.HTTP.ShutdownDelay))
+
+	healthHandler.SetDraining()
+	time.Sleep(cfg.HTTP.ShutdownDelay)
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -73,5 +217,77 @@ func main() {
 		logger.Error("Server Shutdown:", zap.Error(err))
 	}
 
+	// Stop the relay before the deferred broker.Close flushes the producer.
+	stopApp()
+	<-relayDone
+	<-dispatcherDone
+
 	logger.Info("Server exited gracefully")
 }
+
+func dsn(cfg config.DBConfig) string {
+	return fmt.Sprintf("user=%s dbname=%s password=%s host=%s port=%s sslmode=%s", cfg.User, cfg.DBName, cfg.Password, cfg.Host, cfg.Port, cfg.SSLMode)
+}
+
+func connectDB(cfg config.DBConfig) (*sqlx.DB, error) {
+	// otelsql wraps the driver so every statement gets a span.
+	sqlDB, err := otelsql.Open("postgres", dsn(cfg), otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL))
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	db := sqlx.NewDb(sqlDB, "postgres")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// newEventSerializer needs SCHEMA_REGISTRY_URL for Protobuf and Avro, consumers
+// couldn't decode events whose schema is registered in process only.
+func newEventSerializer(cfg *config.Config) (serde.Serializer, error) {
+	var registry serde.Registry
+	if cfg.Events.SchemaRegistryURL != "" {
+		registry = serde.NewHTTPRegistry(cfg.Events.SchemaRegistryURL)
+	} else if cfg.Events.Format != serde.FormatJSON {
+		return nil, fmt.Errorf("SCHEMA_REGISTRY_URL is required for %s events", cfg.Events.Format)
+	}
+
+	subject := cfg.Events.SchemaSubject
+	if subject == "" {
+		subject = cfg.MessageBroker.Topic + "-value"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return serde.NewSerializer(ctx, cfg.Events.Format, registry, subject)
+}
+
+func newJWTVerifier(ctx context.Context, cfg config.JWTConfig, logger *zap.Logger) (*auth.JWTVerifier, error) {
+	roleMapping, err := auth.ParseRoleMapping(cfg.RoleMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := auth.NewKeySet(ctx, cfg.JWKSSource, logger)
+	if err != nil {
+		return nil, err
+	}
+	go keys.Run(ctx, cfg.JWKSRefreshInterval)
+
+	return auth.NewJWTVerifier(keys, auth.JWTConfig{
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+		RolesClaim:  cfg.RolesClaim,
+		RoleMapping: roleMapping,
+		TenantClaim: cfg.TenantClaim,
+		Leeway:      30 * time.Second,
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os/signal"
+	"products/internal/serde"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// runSchemaRegistryCommand serves an in-memory schema registry for local
+// development and returns the process exit code. Schemas are lost on restart.
+func runSchemaRegistryCommand(args []string, logger *zap.Logger) int {
+	flags := flag.NewFlagSet("schema-registry", flag.ContinueOnError)
+	addr := flags.String("addr", ":8085", "listen address")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           serde.NewMemoryRegistry().Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Serving in-memory schema registry", zap.String("addr", *addr))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Schema registry failed", zap.Error(err))
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,8 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+
+DROP POLICY IF EXISTS products_tenant_isolation ON products;
+ALTER TABLE products NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE products DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_products_tenant_created_at;
+ALTER TABLE products DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,17 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tenant_id varchar(64) NOT NULL DEFAULT 'default';
+ALTER TABLE products ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_products_tenant_created_at ON products (tenant_id, created_at);
+
+-- Safety net in case a query misses its tenant filter. The application sets
+-- app.tenant_id per transaction. Superusers bypass row level security, so the
+-- service must connect as a regular role for the policy to apply.
+ALTER TABLE products ENABLE ROW LEVEL SECURITY;
+ALTER TABLE products FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY products_tenant_isolation ON products
+  USING (tenant_id = current_setting('app.tenant_id', true))
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+-- API keys bound to a tenant can only act on that tenant, NULL means any tenant.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id varchar(64);
@@ -0,0 +1,19 @@
+-- Events are written here in the same transaction as the change that caused
+-- them and published to the message broker by the outbox relay.
+CREATE TABLE IF NOT EXISTS outbox (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id varchar(64) NOT NULL,
+  aggregate_id varchar(64) NOT NULL,
+  event_type varchar(100) NOT NULL,
+  topic varchar(255) NOT NULL DEFAULT '',
+  payload BYTEA NOT NULL,
+  headers JSONB NOT NULL DEFAULT '{}',
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (aggregate_id, id) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_sent_at ON outbox (sent_at) WHERE sent_at IS NOT NULL;
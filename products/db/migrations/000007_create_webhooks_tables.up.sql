@@ -0,0 +1,37 @@
+-- Partners subscribe their HTTP endpoints to product events. Deliveries are
+-- written in the same transaction as the event's outbox message and sent by the
+-- webhook dispatcher. Queries filter by tenant_id, the dispatcher reads across tenants.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  tenant_id varchar(64) NOT NULL,
+  url TEXT NOT NULL,
+  -- Empty means every event type.
+  event_types TEXT[] NOT NULL DEFAULT '{}',
+  secret TEXT NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  consecutive_failures INT NOT NULL DEFAULT 0,
+  disabled_reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions (tenant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id BIGSERIAL PRIMARY KEY,
+  subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+  event_id varchar(64) NOT NULL,
+  event_type varchar(100) NOT NULL,
+  payload BYTEA NOT NULL,
+  -- pending, delivered or failed.
+  status varchar(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  response_status INT,
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, id);
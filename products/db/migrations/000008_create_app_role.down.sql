@@ -0,0 +1,6 @@
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT ON SEQUENCES FROM products_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM products_app;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM products_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM products_app;
+REVOKE USAGE ON SCHEMA public FROM products_app;
+DROP ROLE IF EXISTS products_app;
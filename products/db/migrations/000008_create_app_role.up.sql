@@ -0,0 +1,19 @@
+-- The service connects as products_app. Unlike the postgres superuser it is
+-- subject to row level security, so the tenant policies actually apply.
+-- Change the password outside of development with ALTER ROLE products_app PASSWORD '...'.
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'products_app') THEN
+    CREATE ROLE products_app LOGIN PASSWORD 'products_app' NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+  END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO products_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO products_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO products_app;
+REVOKE ALL ON schema_migrations FROM products_app;
+
+-- Tables created by later migrations are covered too.
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO products_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO products_app;
@@ -17,3 +17,16 @@ func IsNotFoundError(err error) bool {
 	var notFoundErr *ErrorNotFound
 	return errors.As(err, &notFoundErr)
 }
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyInvalid  = errors.New("invalid api key")
+	ErrAPIKeyExpired  = errors.New("api key expired")
+)
+
+var ErrTenantRequired = errors.New("tenant is not set in context")
+
+var (
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	ErrWebhookInvalid  = errors.New("invalid webhook subscription")
+)
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestKeySetUnknownKeyRefresh(t *testing.T) {
+	ctx := context.Background()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	doc, err := os.ReadFile(writeJWKS(t, "key-1", &privateKey.PublicKey))
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Reloads block until released, so the lookups overlap with them.
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		w.Write(doc)
+	}))
+	defer server.Close()
+
+	keys, err := NewKeySet(ctx, server.URL, zap.NewNop())
+	require.NoError(t, err)
+	// Let the next unknown id reload right away.
+	keys.lastAttempt = keys.lastAttempt.Add(-2 * minRefreshInterval)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, err := keys.Key(ctx, "key-2")
+			assert.ErrorIs(t, err, ErrUnknownKey)
+		})
+	}
+	assert.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), fetches.Load(), "Concurrent lookups of unknown ids should share one reload")
+
+	_, err = keys.Key(ctx, "key-3")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, int32(2), fetches.Load(), "Unknown ids should not reload again within the minimum refresh interval")
+
+	key, err := keys.Key(ctx, "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, &privateKey.PublicKey, key)
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type KeyProvider interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	// RolesClaim is a dot separated path to the roles claim, e.g. "realm_access.roles".
+	RolesClaim string
+	// RoleMapping maps claim values onto roles. When empty claim values are used as roles as is.
+	RoleMapping map[string]string
+	// TenantClaim is the claim holding the caller's tenant, tokens without it may act on any tenant.
+	TenantClaim string
+	Leeway      time.Duration
+}
+
+type JWTVerifier struct {
+	keys   KeyProvider
+	cfg    JWTConfig
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(keys KeyProvider, cfg JWTConfig) (*JWTVerifier, error) {
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("jwt issuer is required")
+	}
+	if cfg.Audience == "" {
+		return nil, fmt.Errorf("jwt audience is required")
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	)
+
+	return &JWTVerifier{
+		keys:   keys,
+		cfg:    cfg,
+		parser: parser,
+	}, nil
+}
+
+// Verify checks the token signature, expiry, issuer and audience and maps its claims to a Principal.
+func (v *JWTVerifier) Verify(ctx context.Context, rawToken string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	var tenantID string
+	if v.cfg.TenantClaim != "" {
+		tenantID, _ = claims[v.cfg.TenantClaim].(string)
+	}
+
+	return &Principal{
+		Subject:  subject,
+		Method:   MethodJWT,
+		Roles:    v.mapRoles(lookupClaim(claims, v.cfg.RolesClaim)),
+		TenantID: tenantID,
+	}, nil
+}
+
+func (v *JWTVerifier) mapRoles(values []string) []string {
+	if len(v.cfg.RoleMapping) == 0 {
+		return values
+	}
+
+	roles := make([]string, 0, len(values))
+	for _, value := range values {
+		if role, ok := v.cfg.RoleMapping[value]; ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// lookupClaim resolves a dotted claim path to a list of strings. Both JSON
+// arrays and space separated strings (as used by the "scope" claim) are supported.
+func lookupClaim(claims jwt.MapClaims, path string) []string {
+	var current any = map[string]any(claims)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+
+	switch val := current.(type) {
+	case string:
+		return strings.Fields(val)
+	case []any:
+		values := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// ParseRoleMapping parses "claim-value=role,other=role" into a role mapping.
+func ParseRoleMapping(s string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		value, role, ok := strings.Cut(pair, "=")
+		if !ok || value == "" || role == "" {
+			return nil, fmt.Errorf("invalid role mapping %q", pair)
+		}
+		mapping[strings.TrimSpace(value)] = strings.TrimSpace(role)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+
+	doc := map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestJWTVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys, err := NewKeySet(ctx, writeJWKS(t, "key-1", &privateKey.PublicKey), zap.NewNop())
+	require.NoError(t, err)
+
+	verifier, err := NewJWTVerifier(keys, JWTConfig{
+		Issuer:      "https://sso.example.com",
+		Audience:    "products",
+		RolesClaim:  "realm_access.roles",
+		RoleMapping: map[string]string{"catalog-editor": RoleWrite},
+		TenantClaim: "tenant_id",
+	})
+	require.NoError(t, err)
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":          "user-42",
+			"iss":          "https://sso.example.com",
+			"aud":          "products",
+			"exp":          time.Now().Add(time.Hour).Unix(),
+			"realm_access": map[string]any{"roles": []string{"catalog-editor", "unmapped"}},
+			"tenant_id":    "brand-a",
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		principal, err := verifier.Verify(ctx, signToken(t, privateKey, "key-1", validClaims()))
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-42", principal.Subject)
+		assert.Equal(t, MethodJWT, principal.Method)
+		assert.Equal(t, []string{RoleWrite}, principal.Roles, "Only mapped roles should be kept")
+		assert.Equal(t, "brand-a", principal.TenantID)
+	})
+
+	type testCase struct {
+		name  string
+		token func() string
+	}
+
+	cases := []testCase{
+		{name: "Expired", token: func() string {
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return signToken(t, privateKey, "key-1", claims)
+		}},
+		{name: "Missing Expiry", token: func() string {
+			claims := validClaims()
+			delete(claims, "exp")
+			return signToken(t, privateKey, "key-1", claims)
+		}},
+		{name: "Wrong Audience", token: func() string {
+			claims := validClaims()
+			claims["aud"] = "billing"
+			return signToken(t, privateKey, "key-1", claims)
+		}},
+		{name: "Wrong Issuer", token: func() string {
+			claims := validClaims()
+			claims["iss"] = "https://evil.example.com"
+			return signToken(t, privateKey, "key-1", claims)
+		}},
+		{name: "Wrong Signature", token: func() string {
+			return signToken(t, otherKey, "key-1", validClaims())
+		}},
+		{name: "Unsigned", token: func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+			signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			require.NoError(t, err)
+			return signed
+		}},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			principal, err := verifier.Verify(ctx, tCase.token())
+
+			assert.Nil(t, principal)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestParseRoleMapping(t *testing.T) {
+	mapping, err := ParseRoleMapping("catalog-editor=write, catalog-admin=admin")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"catalog-editor": RoleWrite, "catalog-admin": RoleAdmin}, mapping)
+
+	_, err = ParseRoleMapping("catalog-editor")
+	assert.Error(t, err)
+}
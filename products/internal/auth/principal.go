@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Roles are shared by every authentication method: API key scopes map onto
+// them one to one and JWT claims are mapped onto them through configuration.
+const (
+	RoleRead  = "read"
+	RoleWrite = "write"
+	// RoleAdmin is allowed to call every route.
+	RoleAdmin = "admin"
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+type Principal struct {
+	// Subject identifies the caller, e.g. the JWT "sub" claim or "apikey:<id>".
+	Subject string
+	Method  string
+	Roles   []string
+	// TenantID is the tenant the caller is bound to, empty when it may act on any tenant.
+	TenantID string
+}
+
+func (p *Principal) HasAnyRole(roles ...string) bool {
+	if slices.Contains(p.Roles, RoleAdmin) {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// SubjectFromContext returns the authenticated subject or an empty string for anonymous requests.
+func SubjectFromContext(ctx context.Context) string {
+	if p, ok := FromContext(ctx); ok {
+		return p.Subject
+	}
+	return ""
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -10,10 +13,21 @@ type Config struct {
 	HTTP          HTTPConfig
 	DB            DBConfig
 	MessageBroker MessageBrokerConfig
+	JWT           JWTConfig
+	RateLimit     RateLimitConfig
+	Tracing       TracingConfig
+	Outbox        OutboxConfig
+	Events        EventsConfig
+	Webhooks      WebhooksConfig
+	Stream        StreamConfig
+	WebSocket     WebSocketConfig
 }
 
 type HTTPConfig struct {
 	Port string
+	// ShutdownDelay is how long the server keeps serving with a failing readiness
+	// probe after a shutdown signal, so load balancers can drain it first.
+	ShutdownDelay time.Duration
 }
 
 type DBConfig struct {
@@ -23,12 +37,118 @@ type DBConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
 }
 
 type MessageBrokerConfig struct {
-	Endpoint string
-	Topic    string
-	ClientID string
+	// Type is "kafka", "memory" or "nats".
+	Type               string
+	Endpoint           string
+	Topic              string
+	ClientID           string
+	DeliveryMode       string
+	StatisticsInterval time.Duration
+	// Stream is the NATS JetStream stream capturing Topic.
+	Stream string
+}
+
+// JWTConfig enables bearer token authentication when JWKSSource is set.
+type JWTConfig struct {
+	// JWKSSource is a path to a local JWKS file or an http(s) URL.
+	JWKSSource          string
+	JWKSRefreshInterval time.Duration
+	Issuer              string
+	Audience            string
+	RolesClaim          string
+	// RoleMapping is "claim-value=role,..." e.g. "catalog-editor=write,catalog-admin=admin".
+	RoleMapping string
+	TenantClaim string
+}
+
+// RateLimitConfig holds token bucket limits per client, in requests per second.
+// A zero rate or burst disables a limit.
+type RateLimitConfig struct {
+	IPRate     float64
+	IPBurst    int
+	ReadRate   float64
+	ReadBurst  int
+	WriteRate  float64
+	WriteBurst int
+}
+
+type TracingConfig struct {
+	// Exporter is one of none, stdout, file or otlp.
+	Exporter    string
+	FilePath    string
+	SampleRatio float64
+}
+
+// OutboxConfig controls the relay publishing the outbox table.
+type OutboxConfig struct {
+	// PollInterval is the fallback when insert notifications are missed.
+	PollInterval    time.Duration
+	BatchSize       int
+	PublishTimeout  time.Duration
+	MinBackoff      time.Duration
+	MaxBackoff      time.Duration
+	Retention       time.Duration
+	CleanupInterval time.Duration
+	// LeaderCheckInterval is how often replicas try to take over the relay and the
+	// leader checks it still holds its lock.
+	LeaderCheckInterval time.Duration
+}
+
+// WebhooksConfig controls the dispatcher sending webhook deliveries.
+type WebhooksConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxAttempts  int
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	// DisableAfter is the number of consecutive failed attempts after which a
+	// subscription is disabled.
+	DisableAfter    int
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+// StreamConfig controls the product event stream served to clients of this replica.
+type StreamConfig struct {
+	// HistorySize is how many recent events are kept for clients resuming with Last-Event-ID.
+	HistorySize int
+	// BufferSize is how many events may queue up for a client before it is
+	// disconnected as too slow.
+	BufferSize int
+	// MaxClients limits concurrent stream connections, 0 means no limit.
+	MaxClients        int
+	HeartbeatInterval time.Duration
+	WriteTimeout      time.Duration
+}
+
+// WebSocketConfig controls the product event WebSocket endpoint.
+type WebSocketConfig struct {
+	MaxConnections   int
+	MaxSubscriptions int
+	PingInterval     time.Duration
+	PongTimeout      time.Duration
+	WriteTimeout     time.Duration
+	// AllowedOrigins lists the browser origins allowed besides the API's own, "*" allows any.
+	AllowedOrigins []string
+}
+
+type EventsConfig struct {
+	// Format is json, protobuf or avro.
+	Format string
+	// SchemaRegistryURL points to a Confluent compatible schema registry. It is
+	// required for protobuf and avro and ignored for json.
+	SchemaRegistryURL string
+	// SchemaSubject defaults to "<topic>-value".
+	SchemaSubject string
 }
 
 func Load() *Config {
@@ -37,7 +157,8 @@ func Load() *Config {
 
 	return &Config{
 		HTTP: HTTPConfig{
-			Port: getEnv("HTTP_PORT", "8081"),
+			Port:          getEnv("HTTP_PORT", "8081"),
+			ShutdownDelay: getEnvDuration("HTTP_SHUTDOWN_DELAY", 5*time.Second),
 		},
 		DB: DBConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -46,11 +167,85 @@ func Load() *Config {
 			Password: getEnv("DB_PASSWORD", "root"),
 			DBName:   getEnv("DB_NAME", "products"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+			MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime: getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
 		},
 		MessageBroker: MessageBrokerConfig{
+			Type:     getEnv("MESSAGE_BROKER_TYPE", "kafka"),
 			Endpoint: getEnv("MESSAGE_BROKER_ENDPOINT", "localhost:9094"),
 			Topic:    getEnv("MESSAGE_BROKER_TOPIC", "product-events"),
 			ClientID: getEnv("MESSAGE_BROKER_CLIENT_ID", "product-service"),
+			Stream:   getEnv("MESSAGE_BROKER_STREAM", "PRODUCT_EVENTS"),
+
+			DeliveryMode:       getEnv("MESSAGE_BROKER_DELIVERY_MODE", "sync"),
+			StatisticsInterval: getEnvDuration("MESSAGE_BROKER_STATISTICS_INTERVAL", 15*time.Second),
+		},
+		JWT: JWTConfig{
+			JWKSSource:          getEnv("JWT_JWKS_SOURCE", ""),
+			JWKSRefreshInterval: getEnvDuration("JWT_JWKS_REFRESH_INTERVAL", 15*time.Minute),
+			Issuer:              getEnv("JWT_ISSUER", ""),
+			Audience:            getEnv("JWT_AUDIENCE", "products"),
+			RolesClaim:          getEnv("JWT_ROLES_CLAIM", "roles"),
+			RoleMapping:         getEnv("JWT_ROLE_MAPPING", ""),
+			TenantClaim:         getEnv("JWT_TENANT_CLAIM", "tenant_id"),
+		},
+		RateLimit: RateLimitConfig{
+			IPRate:     getEnvFloat("RATE_LIMIT_IP_RPS", 50),
+			IPBurst:    getEnvInt("RATE_LIMIT_IP_BURST", 100),
+			ReadRate:   getEnvFloat("RATE_LIMIT_READ_RPS", 20),
+			ReadBurst:  getEnvInt("RATE_LIMIT_READ_BURST", 40),
+			WriteRate:  getEnvFloat("RATE_LIMIT_WRITE_RPS", 5),
+			WriteBurst: getEnvInt("RATE_LIMIT_WRITE_BURST", 10),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnv("TRACING_EXPORTER", "none"),
+			FilePath:    getEnv("TRACING_FILE_PATH", "traces.jsonl"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		Outbox: OutboxConfig{
+			PollInterval:    getEnvDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+			BatchSize:       getEnvInt("OUTBOX_BATCH_SIZE", 100),
+			PublishTimeout:  getEnvDuration("OUTBOX_PUBLISH_TIMEOUT", 10*time.Second),
+			MinBackoff:      getEnvDuration("OUTBOX_MIN_BACKOFF", time.Second),
+			MaxBackoff:      getEnvDuration("OUTBOX_MAX_BACKOFF", 5*time.Minute),
+			Retention:       getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+			CleanupInterval: getEnvDuration("OUTBOX_CLEANUP_INTERVAL", time.Hour),
+
+			LeaderCheckInterval: getEnvDuration("OUTBOX_LEADER_CHECK_INTERVAL", 5*time.Second),
+		},
+		Events: EventsConfig{
+			Format:            getEnv("EVENTS_FORMAT", "json"),
+			SchemaRegistryURL: getEnv("SCHEMA_REGISTRY_URL", ""),
+			SchemaSubject:     getEnv("EVENTS_SCHEMA_SUBJECT", ""),
+		},
+		Webhooks: WebhooksConfig{
+			PollInterval:    getEnvDuration("WEBHOOKS_POLL_INTERVAL", 2*time.Second),
+			BatchSize:       getEnvInt("WEBHOOKS_BATCH_SIZE", 50),
+			Timeout:         getEnvDuration("WEBHOOKS_TIMEOUT", 10*time.Second),
+			MaxAttempts:     getEnvInt("WEBHOOKS_MAX_ATTEMPTS", 10),
+			MinBackoff:      getEnvDuration("WEBHOOKS_MIN_BACKOFF", 10*time.Second),
+			MaxBackoff:      getEnvDuration("WEBHOOKS_MAX_BACKOFF", time.Hour),
+			DisableAfter:    getEnvInt("WEBHOOKS_DISABLE_AFTER", 50),
+			Retention:       getEnvDuration("WEBHOOKS_RETENTION", 7*24*time.Hour),
+			CleanupInterval: getEnvDuration("WEBHOOKS_CLEANUP_INTERVAL", time.Hour),
+		},
+		Stream: StreamConfig{
+			HistorySize:       getEnvInt("STREAM_HISTORY_SIZE", 1000),
+			BufferSize:        getEnvInt("STREAM_BUFFER_SIZE", 64),
+			MaxClients:        getEnvInt("STREAM_MAX_CLIENTS", 1000),
+			HeartbeatInterval: getEnvDuration("STREAM_HEARTBEAT_INTERVAL", 15*time.Second),
+			WriteTimeout:      getEnvDuration("STREAM_WRITE_TIMEOUT", 10*time.Second),
+		},
+		WebSocket: WebSocketConfig{
+			MaxConnections:   getEnvInt("WS_MAX_CONNECTIONS", 1000),
+			MaxSubscriptions: getEnvInt("WS_MAX_SUBSCRIPTIONS", 20),
+			PingInterval:     getEnvDuration("WS_PING_INTERVAL", 30*time.Second),
+			PongTimeout:      getEnvDuration("WS_PONG_TIMEOUT", 60*time.Second),
+			WriteTimeout:     getEnvDuration("WS_WRITE_TIMEOUT", 10*time.Second),
+			AllowedOrigins:   getEnvList("WS_ALLOWED_ORIGINS"),
 		},
 	}
 }
@@ -61,3 +256,41 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList splits a comma separated value, dropping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for value := range strings.SplitSeq(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
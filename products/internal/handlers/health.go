@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HealthCheck reports whether a dependency is usable, it must respect ctx's deadline.
+type HealthCheck func(ctx context.Context) error
+
+type HealthHandler struct {
+	checks   map[string]HealthCheck
+	timeout  time.Duration
+	draining atomic.Bool
+	logger   *zap.Logger
+}
+
+func NewHealthHandler(checks map[string]HealthCheck, timeout time.Duration, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+		logger:  logger.Named("HealthHandler"),
+	}
+}
+
+// SetDraining makes the readiness probe fail from now on, so load balancers
+// stop sending traffic before the server shuts down.
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
+
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "draining",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		ready   = true
+		results = make(map[string]gin.H, len(h.checks))
+	)
+
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check(ctx)
+			result := gin.H{
+				"status":   "ok",
+				"duration": time.Since(start).String(),
+			}
+			if err != nil {
+				h.logger.Warn("Health check failed", zap.String("check", name), zap.Error(err))
+				result["status"] = "error"
+				result["error"] = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = result
+			if err != nil {
+				ready = false
+			}
+		}()
+	}
+	wg.Wait()
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": results,
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type healthResponse struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"checks"`
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	type testCase struct {
+		name           string
+		checks         map[string]HealthCheck
+		draining       bool
+		expectedStatus int
+		expectedBody   string
+		failedCheck    string
+	}
+
+	cases := []testCase{
+		{name: "All Dependencies Ready", checks: map[string]HealthCheck{"postgres": ok, "kafka": ok}, expectedStatus: http.StatusOK, expectedBody: "ok"},
+		{name: "Dependency Failing", checks: map[string]HealthCheck{"postgres": ok, "kafka": failing}, expectedStatus: http.StatusServiceUnavailable, expectedBody: "unavailable", failedCheck: "kafka"},
+		{name: "Dependency Timing Out", checks: map[string]HealthCheck{"postgres": slow, "kafka": ok}, expectedStatus: http.StatusServiceUnavailable, expectedBody: "unavailable", failedCheck: "postgres"},
+		{name: "Draining", checks: map[string]HealthCheck{"postgres": ok}, draining: true, expectedStatus: http.StatusServiceUnavailable, expectedBody: "draining"},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			handler := NewHealthHandler(tCase.checks, 50*time.Millisecond, zap.NewNop())
+			if tCase.draining {
+				handler.SetDraining()
+			}
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest("GET", "/health/ready", nil)
+
+			handler.Ready(ctx)
+
+			assert.Equal(t, tCase.expectedStatus, w.Code)
+
+			var resp healthResponse
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			assert.NoError(t, err, "Response body should be valid JSON")
+			assert.Equal(t, tCase.expectedBody, resp.Status)
+
+			if !tCase.draining {
+				assert.Len(t, resp.Checks, len(tCase.checks), "Every dependency should be reported")
+			}
+			if tCase.failedCheck != "" {
+				assert.Equal(t, "error", resp.Checks[tCase.failedCheck].Status)
+				assert.NotEmpty(t, resp.Checks[tCase.failedCheck].Error)
+			}
+		})
+	}
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	handler := NewHealthHandler(nil, time.Second, zap.NewNop())
+	handler.SetDraining()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/health/live", nil)
+
+	handler.Live(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Liveness shouldn't depend on draining")
+}
@@ -1,23 +1,80 @@
 package handlers
 
 import (
+	"products/internal/auth"
 	middleware "products/internal/middlewares"
+	"products/internal/ratelimit"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
 )
 
-func SetupRoutes(productsHandler *ProductsHandler, logger *zap.Logger) *gin.Engine {
+// routePolicies declares which roles may call each route. Routes missing here are denied.
+var routePolicies = middleware.RoutePolicy{
+	"GET /products":        {Public: true},
+	"GET /products/stream": {Roles: []string{auth.RoleRead}},
+	"GET /ws":              {Roles: []string{auth.RoleRead}},
+	"POST /products":       {Roles: []string{auth.RoleWrite}},
+	"DELETE /products/:id": {Roles: []string{auth.RoleWrite}},
+	"GET /metrics":         {Public: true},
+	"GET /health/live":     {Public: true},
+	"GET /health/ready":    {Public: true},
+
+	"POST /webhooks":               {Roles: []string{auth.RoleAdmin}},
+	"GET /webhooks":                {Roles: []string{auth.RoleAdmin}},
+	"GET /webhooks/:id":            {Roles: []string{auth.RoleAdmin}},
+	"PATCH /webhooks/:id":          {Roles: []string{auth.RoleAdmin}},
+	"DELETE /webhooks/:id":         {Roles: []string{auth.RoleAdmin}},
+	"GET /webhooks/:id/deliveries": {Roles: []string{auth.RoleAdmin}},
+}
+
+type RateLimits struct {
+	Store ratelimit.Store
+	// IP limits every client by IP before it is authenticated, which also throttles guessing credentials.
+	IP    ratelimit.Limit
+	Read  ratelimit.Limit
+	Write ratelimit.Limit
+}
+
+func SetupRoutes(productsHandler *ProductsHandler, streamHandler *StreamHandler, wsHandler *WebSocketHandler, webhooksHandler *WebhooksHandler, healthHandler *HealthHandler, tokens middleware.TokenVerifier, apiKeys middleware.APIKeyAuthenticator, rateLimits RateLimits, logger *zap.Logger) *gin.Engine {
 	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(otelgin.Middleware("products", otelgin.WithGinFilter(func(c *gin.Context) bool {
+		switch c.FullPath() {
+		case "/metrics", "/health/live", "/health/ready":
+			return false
+		}
+		return true
+	})))
+	router.Use(middleware.HTTPMetrics())
 	router.Use(middleware.ZapLoggerMiddleware(logger))
 	router.Use(middleware.ZapRecoveryMiddleware(logger, true))
+	router.Use(middleware.RateLimit(rateLimits.Store, "ip", rateLimits.IP, logger))
+	router.Use(middleware.Authenticate(tokens, apiKeys, logger))
+	router.Use(middleware.Authorize(routePolicies, logger))
+	router.Use(middleware.ResolveTenant(logger))
+
+	readLimit := middleware.RateLimit(rateLimits.Store, "read", rateLimits.Read, logger)
+	writeLimit := middleware.RateLimit(rateLimits.Store, "write", rateLimits.Write, logger)
+
+	router.GET("/products", readLimit, productsHandler.List)
+	router.GET("/products/stream", readLimit, streamHandler.Stream)
+	router.GET("/ws", readLimit, wsHandler.Connect)
+	router.POST("/products", writeLimit, productsHandler.Create)
+	router.DELETE("/products/:id", writeLimit, productsHandler.Delete)
 
-	router.GET("/products", productsHandler.List)
-	router.POST("/products", productsHandler.Create)
-	router.DELETE("/products/:id", productsHandler.Delete)
+	router.POST("/webhooks", writeLimit, webhooksHandler.Create)
+	router.GET("/webhooks", readLimit, webhooksHandler.List)
+	router.GET("/webhooks/:id", readLimit, webhooksHandler.Get)
+	router.PATCH("/webhooks/:id", writeLimit, webhooksHandler.Update)
+	router.DELETE("/webhooks/:id", writeLimit, webhooksHandler.Delete)
+	router.GET("/webhooks/:id/deliveries", readLimit, webhooksHandler.Deliveries)
 
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/health/live", healthHandler.Live)
+	router.GET("/health/ready", healthHandler.Ready)
 
 	return router
 }
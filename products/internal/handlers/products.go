@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"products/internal/apperrors"
+	loggerPkg "products/internal/logger"
 	"products/internal/models"
 	"products/internal/utils"
 
@@ -30,11 +31,12 @@ func NewProductsHandler(pService ProductService, logger *zap.Logger) *ProductsHa
 }
 
 func (h *ProductsHandler) Create(c *gin.Context) {
+	logger := loggerPkg.WithContext(c.Request.Context(), h.logger)
 	var createDTO models.CreateProductDTO
 
 	err := c.ShouldBindJSON(&createDTO)
 	if err != nil {
-		h.logger.Error("CreateProductDTO binding error:", zap.Error(err))
+		logger.Error("CreateProductDTO binding error:", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -44,7 +46,7 @@ func (h *ProductsHandler) Create(c *gin.Context) {
 
 	product, err := h.pService.Create(c.Request.Context(), &createDTO)
 	if err != nil {
-		h.logger.Error("Error creating product:", zap.Error(err))
+		logger.Error("Error creating product:", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Internal Server Error",
@@ -59,10 +61,11 @@ func (h *ProductsHandler) Create(c *gin.Context) {
 }
 
 func (h *ProductsHandler) Delete(c *gin.Context) {
+	logger := loggerPkg.WithContext(c.Request.Context(), h.logger)
 	var deleteDTO models.DeleteProductDTO
 	err := c.ShouldBindUri(&deleteDTO)
 	if err != nil {
-		h.logger.Error("DeleteProductDTO binding error:", zap.Error(err))
+		logger.Error("DeleteProductDTO binding error:", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -81,7 +84,7 @@ func (h *ProductsHandler) Delete(c *gin.Context) {
 			return
 		}
 
-		h.logger.Error("Error deleting product:", zap.Error(err))
+		logger.Error("Error deleting product:", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Internal Server Error",
@@ -96,10 +99,11 @@ func (h *ProductsHandler) Delete(c *gin.Context) {
 }
 
 func (h *ProductsHandler) List(c *gin.Context) {
+	logger := loggerPkg.WithContext(c.Request.Context(), h.logger)
 	var listDTO models.ListProductsDTO
 	err := c.ShouldBindQuery(&listDTO)
 	if err != nil {
-		h.logger.Error("ListProductsDTO binding error:", zap.Error(err))
+		logger.Error("ListProductsDTO binding error:", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -120,7 +124,7 @@ func (h *ProductsHandler) List(c *gin.Context) {
 	products, total, err := h.pService.List(c.Request.Context(), &listDTO)
 
 	if err != nil {
-		h.logger.Error("Error listing products:", zap.Error(err))
+		logger.Error("Error listing products:", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "InternalServerError",
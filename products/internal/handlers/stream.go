@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	loggerPkg "products/internal/logger"
+	"products/internal/stream"
+	"products/internal/tenant"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// streamRetry is the reconnect delay suggested to clients, in milliseconds.
+const streamRetry = 3000
+
+type StreamHub interface {
+	Subscribe(filter stream.Filter, lastEventID string) (*stream.Subscription, error)
+	Unsubscribe(sub *stream.Subscription)
+}
+
+type StreamHandler struct {
+	hub       StreamHub
+	heartbeat time.Duration
+	// writeTimeout bounds a single write, so a client that stopped reading
+	// doesn't keep its handler around.
+	writeTimeout time.Duration
+	logger       *zap.Logger
+}
+
+func NewStreamHandler(hub StreamHub, heartbeat, writeTimeout time.Duration, logger *zap.Logger) *StreamHandler {
+	return &StreamHandler{
+		hub:          hub,
+		heartbeat:    heartbeat,
+		writeTimeout: writeTimeout,
+		logger:       logger.Named("StreamHandler"),
+	}
+}
+
+// Stream sends the tenant's product events as Server-Sent Events until the
+// client goes away. The types and product_id query parameters narrow the
+// events, both take a comma separated list or can be repeated. A client
+// reconnecting with the Last-Event-ID header (or the last_event_id query
+// parameter) gets the events it missed, as far as they are still buffered;
+// otherwise it gets a resync event and should reload the products.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := loggerPkg.WithContext(ctx, h.logger)
+
+	tenantID, _ := tenant.FromContext(ctx)
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub, err := h.hub.Subscribe(stream.Filter{
+		TenantID:   tenantID,
+		Types:      splitQuery(c.QueryArray("types")),
+		ProductIDs: splitQuery(c.QueryArray("product_id")),
+	}, lastEventID)
+	if err != nil {
+		if !errors.Is(err, stream.ErrTooManyClients) && !errors.Is(err, stream.ErrClosed) {
+			logger.Error("Error subscribing to product events:", zap.Error(err))
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keeps reverse proxies like nginx from buffering the stream.
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	write := func(format string, args ...any) error {
+		// Not every writer supports deadlines, the stream works without them.
+		_ = rc.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := write("retry: %d\n\n", streamRetry); err != nil {
+		return
+	}
+	if sub.Resync() {
+		if err := write("event: resync\ndata: {}\n\n"); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if err := write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					logger.Warn("Disconnected product stream client that fell behind")
+				}
+				return
+			}
+			if err := write("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// splitQuery flattens repeated and comma separated query values.
+func splitQuery(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for part := range strings.SplitSeq(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"contracts/productevent"
+	"net/http"
+	"net/http/httptest"
+	"products/internal/stream"
+	"products/internal/tenant"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newStreamServer(t *testing.T, hub *stream.Hub, heartbeat time.Duration) *httptest.Server {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), "acme"))
+	})
+	router.GET("/products/stream", NewStreamHandler(hub, heartbeat, time.Second, zap.NewNop()).Stream)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// sseReader reads the blocks of an event stream, a block ends with an empty line.
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+func openStream(t *testing.T, ctx context.Context, url, lastEventID string) (*http.Response, *sseReader) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, &sseReader{scanner: bufio.NewScanner(resp.Body)}
+}
+
+func (r *sseReader) next(t *testing.T) []string {
+	var lines []string
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+	t.Fatalf("stream ended: %v", r.scanner.Err())
+	return nil
+}
+
+func TestStreamHandler(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+	srv := newStreamServer(t, hub, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, events := openStream(t, ctx, srv.URL+"/products/stream?types=product_created,product_deleted&product_id=p2", "")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"retry: 3000"}, events.next(t))
+
+	created := productevent.New(productevent.Created, productevent.Product{ID: "p1"}, productevent.Metadata{TenantID: "acme"})
+	deleted := productevent.New(productevent.Deleted, productevent.Product{ID: "p2"}, productevent.Metadata{TenantID: "acme"})
+	otherTenant := productevent.New(productevent.Deleted, productevent.Product{ID: "p2"}, productevent.Metadata{TenantID: "globex"})
+	hub.Publish(created)
+	hub.Publish(otherTenant)
+	hub.Publish(deleted)
+
+	block := events.next(t)
+	require.Len(t, block, 3)
+	assert.True(t, strings.HasPrefix(block[0], "id: "))
+	assert.Equal(t, "event: product_deleted", block[1])
+	decoded, err := productevent.DecodeJSON([]byte(strings.TrimPrefix(block[2], "data: ")))
+	require.NoError(t, err)
+	assert.Equal(t, deleted.ID, decoded.ID)
+
+	t.Run("Resume", func(t *testing.T) {
+		firstID := strings.TrimPrefix(block[0], "id: ")
+		later := productevent.New(productevent.Created, productevent.Product{ID: "p2"}, productevent.Metadata{TenantID: "acme"})
+		hub.Publish(later)
+
+		_, resumed := openStream(t, ctx, srv.URL+"/products/stream?product_id=p2", firstID)
+
+		assert.Equal(t, []string{"retry: 3000"}, resumed.next(t))
+		block := resumed.next(t)
+		require.Len(t, block, 3)
+		assert.Equal(t, "event: product_created", block[1])
+		assert.Contains(t, block[2], later.ID)
+	})
+
+	t.Run("Resync", func(t *testing.T) {
+		_, resumed := openStream(t, ctx, srv.URL+"/products/stream?product_id=none", "unknown-1")
+
+		assert.Equal(t, []string{"retry: 3000"}, resumed.next(t))
+		assert.Equal(t, []string{"event: resync", "data: {}"}, resumed.next(t))
+	})
+}
+
+func TestStreamHandlerHeartbeat(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+	srv := newStreamServer(t, hub, 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, events := openStream(t, ctx, srv.URL+"/products/stream", "")
+
+	assert.Equal(t, []string{"retry: 3000"}, events.next(t))
+	assert.Equal(t, []string{": heartbeat"}, events.next(t))
+}
+
+func TestStreamHandlerTooManyClients(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10, MaxClients: 1}, zap.NewNop())
+	defer hub.Close()
+	srv := newStreamServer(t, hub, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, events := openStream(t, ctx, srv.URL+"/products/stream", "")
+	events.next(t)
+
+	resp, _ := openStream(t, ctx, srv.URL+"/products/stream", "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestStreamHandlerEndsOnHubClose(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	srv := newStreamServer(t, hub, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, events := openStream(t, ctx, srv.URL+"/products/stream", "")
+	events.next(t)
+
+	hub.Close()
+
+	assert.False(t, events.scanner.Scan(), "The stream should end when the hub closes")
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"products/internal/apperrors"
+	loggerPkg "products/internal/logger"
+	"products/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WebhooksService interface {
+	Create(ctx context.Context, createDTO *models.CreateWebhookDTO) (*models.WebhookSubscription, string, error)
+	Get(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	List(ctx context.Context) ([]models.WebhookSubscription, error)
+	Update(ctx context.Context, id string, updateDTO *models.UpdateWebhookDTO) (*models.WebhookSubscription, error)
+	Delete(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	Deliveries(ctx context.Context, id string, limit int) ([]models.WebhookDelivery, error)
+}
+
+type WebhooksHandler struct {
+	wService WebhooksService
+	logger   *zap.Logger
+}
+
+func NewWebhooksHandler(wService WebhooksService, logger *zap.Logger) *WebhooksHandler {
+	return &WebhooksHandler{
+		wService: wService,
+		logger:   logger.Named("WebhooksHandler"),
+	}
+}
+
+// Create responds with the subscription and its secret, which isn't returned again.
+func (h *WebhooksHandler) Create(c *gin.Context) {
+	var createDTO models.CreateWebhookDTO
+	if err := c.ShouldBindJSON(&createDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, secret, err := h.wService.Create(c.Request.Context(), &createDTO)
+	if err != nil {
+		h.error(c, "Error creating webhook subscription:", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    sub,
+		"secret":  secret,
+	})
+}
+
+func (h *WebhooksHandler) List(c *gin.Context) {
+	subs, err := h.wService.List(c.Request.Context())
+	if err != nil {
+		h.error(c, "Error listing webhook subscriptions:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    subs,
+	})
+}
+
+func (h *WebhooksHandler) Get(c *gin.Context) {
+	var idDTO models.WebhookIDDTO
+	if err := c.ShouldBindUri(&idDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, err := h.wService.Get(c.Request.Context(), idDTO.ID)
+	if err != nil {
+		h.error(c, "Error getting webhook subscription:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+func (h *WebhooksHandler) Update(c *gin.Context) {
+	var idDTO models.WebhookIDDTO
+	if err := c.ShouldBindUri(&idDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+	var updateDTO models.UpdateWebhookDTO
+	if err := c.ShouldBindJSON(&updateDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, err := h.wService.Update(c.Request.Context(), idDTO.ID, &updateDTO)
+	if err != nil {
+		h.error(c, "Error updating webhook subscription:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+func (h *WebhooksHandler) Delete(c *gin.Context) {
+	var idDTO models.WebhookIDDTO
+	if err := c.ShouldBindUri(&idDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	sub, err := h.wService.Delete(c.Request.Context(), idDTO.ID)
+	if err != nil {
+		h.error(c, "Error deleting webhook subscription:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// Deliveries responds with the delivery log of a subscription, newest first.
+func (h *WebhooksHandler) Deliveries(c *gin.Context) {
+	var idDTO models.WebhookIDDTO
+	if err := c.ShouldBindUri(&idDTO); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+	var query struct {
+		Limit int `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	deliveries, err := h.wService.Deliveries(c.Request.Context(), idDTO.ID, query.Limit)
+	if err != nil {
+		h.error(c, "Error listing webhook deliveries:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    deliveries,
+	})
+}
+
+func (h *WebhooksHandler) badRequest(c *gin.Context, err error) {
+	loggerPkg.WithContext(c.Request.Context(), h.logger).Error("Webhook request binding error:", zap.Error(err))
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+func (h *WebhooksHandler) error(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, apperrors.ErrWebhookInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		loggerPkg.WithContext(c.Request.Context(), h.logger).Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Internal Server Error",
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"contracts/productevent"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	loggerPkg "products/internal/logger"
+	"products/internal/metrics"
+	"products/internal/stream"
+	"products/internal/tenant"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// wsMaxMessageSize limits client messages, which are small subscribe and unsubscribe requests.
+	wsMaxMessageSize = 4 << 10
+	wsMaxIDLength    = 64
+)
+
+type WebSocketConfig struct {
+	MaxConnections int
+	// MaxSubscriptions limits the subscriptions of a single connection.
+	MaxSubscriptions int
+	PingInterval     time.Duration
+	// PongTimeout is how long a connection may stay silent, including pongs,
+	// before it is closed. It must be longer than PingInterval.
+	PongTimeout  time.Duration
+	WriteTimeout time.Duration
+	// AllowedOrigins lists the origins browsers may connect from besides the
+	// API's own, "*" allows any.
+	AllowedOrigins []string
+}
+
+// wsRequest is a message from the client. Subscribing again with an existing id
+// replaces that subscription's filter.
+type wsRequest struct {
+	Action string   `json:"action"`
+	ID     string   `json:"id"`
+	Filter wsFilter `json:"filter"`
+}
+
+type wsFilter struct {
+	Types      []string            `json:"types"`
+	ProductIDs []string            `json:"product_ids"`
+	MinPrice   *productevent.Cents `json:"min_price"`
+	MaxPrice   *productevent.Cents `json:"max_price"`
+}
+
+// wsMessage is a message to the client. Events are sent once per connection
+// with the ids of every subscription they match.
+type wsMessage struct {
+	Type          string          `json:"type"`
+	ID            string          `json:"id,omitempty"`
+	Subscriptions []string        `json:"subscriptions,omitempty"`
+	Event         json.RawMessage `json:"event,omitempty"`
+	Error         string          `json:"error,omitempty"`
+}
+
+type WebSocketHandler struct {
+	hub         StreamHub
+	cfg         WebSocketConfig
+	upgrader    websocket.Upgrader
+	connections atomic.Int64
+	logger      *zap.Logger
+}
+
+func NewWebSocketHandler(hub StreamHub, cfg WebSocketConfig, logger *zap.Logger) *WebSocketHandler {
+	h := &WebSocketHandler{
+		hub:    hub,
+		cfg:    cfg,
+		logger: logger.Named("WebSocketHandler"),
+	}
+	h.upgrader = websocket.Upgrader{
+		HandshakeTimeout: cfg.WriteTimeout,
+		CheckOrigin:      h.checkOrigin,
+	}
+	return h
+}
+
+// Connect upgrades the request to a WebSocket, on which the client subscribes
+// to the tenant's product events with filters and receives the matching ones.
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := loggerPkg.WithContext(ctx, h.logger)
+
+	if n := h.connections.Add(1); h.cfg.MaxConnections > 0 && n > int64(h.cfg.MaxConnections) {
+		h.connections.Add(-1)
+		metrics.WebSocketConnectionsRejected.Inc()
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "too many websocket connections",
+		})
+		return
+	}
+	defer h.connections.Add(-1)
+
+	tenantID, _ := tenant.FromContext(ctx)
+	// The connection receives all of the tenant's events and matches them against its own subscriptions.
+	sub, err := h.hub.Subscribe(stream.Filter{TenantID: tenantID}, "")
+	if err != nil {
+		if !errors.Is(err, stream.ErrTooManyClients) && !errors.Is(err, stream.ErrClosed) {
+			logger.Error("Error subscribing to product events:", zap.Error(err))
+		}
+		metrics.WebSocketConnectionsRejected.Inc()
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	// Upgrade responds with an error itself.
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Warn("WebSocket upgrade failed:", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	metrics.WebSocketConnections.Inc()
+	defer metrics.WebSocketConnections.Dec()
+
+	wc := &wsConn{
+		conn:    conn,
+		cfg:     h.cfg,
+		tenant:  tenantID,
+		filters: map[string]stream.Filter{},
+		replies: make(chan wsMessage, 16),
+		done:    make(chan struct{}),
+		logger:  logger,
+	}
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		wc.writeLoop(sub)
+	}()
+
+	wc.readLoop(writerDone)
+	close(wc.done)
+	<-writerDone
+}
+
+func (h *WebSocketHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(h.cfg.AllowedOrigins, "*") || slices.Contains(h.cfg.AllowedOrigins, origin) {
+		return true
+	}
+	return origin == "http://"+r.Host || origin == "https://"+r.Host
+}
+
+// wsConn is a single WebSocket connection. The read loop handles client
+// requests, the write loop owns all writes to the connection.
+type wsConn struct {
+	conn   *websocket.Conn
+	cfg    WebSocketConfig
+	tenant string
+
+	mu      sync.Mutex
+	filters map[string]stream.Filter
+
+	replies chan wsMessage
+	// done is closed when the read loop ended.
+	done   chan struct{}
+	logger *zap.Logger
+}
+
+func (c *wsConn) readLoop(writerDone <-chan struct{}) {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				c.logger.Debug("WebSocket read error:", zap.Error(err))
+			}
+			return
+		}
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongTimeout))
+
+		select {
+		case c.replies <- c.handle(data):
+		case <-writerDone:
+			return
+		}
+	}
+}
+
+func (c *wsConn) handle(data []byte) wsMessage {
+	var req wsRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return wsMessage{Type: "error", Error: "invalid message: " + err.Error()}
+	}
+	if req.ID == "" || len(req.ID) > wsMaxIDLength {
+		return wsMessage{Type: "error", ID: req.ID, Error: fmt.Sprintf("id is required and at most %d characters", wsMaxIDLength)}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch req.Action {
+	case "subscribe":
+		f := req.Filter
+		if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+			return wsMessage{Type: "error", ID: req.ID, Error: "min_price is greater than max_price"}
+		}
+		if _, ok := c.filters[req.ID]; !ok && c.cfg.MaxSubscriptions > 0 && len(c.filters) >= c.cfg.MaxSubscriptions {
+			return wsMessage{Type: "error", ID: req.ID, Error: fmt.Sprintf("at most %d subscriptions per connection", c.cfg.MaxSubscriptions)}
+		}
+		c.filters[req.ID] = stream.Filter{
+			TenantID:   c.tenant,
+			Types:      f.Types,
+			ProductIDs: f.ProductIDs,
+			MinPrice:   f.MinPrice,
+			MaxPrice:   f.MaxPrice,
+		}
+		return wsMessage{Type: "subscribed", ID: req.ID}
+	case "unsubscribe":
+		if _, ok := c.filters[req.ID]; !ok {
+			return wsMessage{Type: "error", ID: req.ID, Error: "unknown subscription"}
+		}
+		delete(c.filters, req.ID)
+		return wsMessage{Type: "unsubscribed", ID: req.ID}
+	default:
+		return wsMessage{Type: "error", ID: req.ID, Error: fmt.Sprintf("unknown action %q", req.Action)}
+	}
+}
+
+// matching returns the ids of the subscriptions e matches, sorted.
+func (c *wsConn) matching(e *stream.Event) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ids []string
+	for id, filter := range c.filters {
+		if filter.Match(e) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (c *wsConn) writeLoop(sub *stream.Subscription) {
+	ping := time.NewTicker(c.cfg.PingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.replies:
+			err = c.write(msg)
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					c.logger.Warn("Disconnected websocket client that fell behind")
+					c.close(websocket.CloseTryAgainLater, "too slow")
+				} else {
+					c.close(websocket.CloseGoingAway, "server shutting down")
+				}
+				return
+			}
+			if ids := c.matching(e); len(ids) > 0 {
+				err = c.write(wsMessage{Type: "event", Subscriptions: ids, Event: e.Data})
+			}
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.cfg.WriteTimeout))
+		}
+		if err != nil {
+			// Unblocks the read loop.
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+func (c *wsConn) write(msg wsMessage) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+	return c.conn.WriteJSON(msg)
+}
+
+// close sends a close frame and closes the connection, which ends the read loop.
+func (c *wsConn) close(code int, text string) {
+	_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(c.cfg.WriteTimeout))
+	c.conn.Close()
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"contracts/productevent"
+	"net/http"
+	"net/http/httptest"
+	"products/internal/metrics"
+	"products/internal/stream"
+	"products/internal/tenant"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newWebSocketServer(t *testing.T, hub *stream.Hub, cfg WebSocketConfig) *httptest.Server {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), "acme"))
+	})
+	router.GET("/ws", NewWebSocketHandler(hub, cfg, zap.NewNop()).Connect)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testWebSocketConfig() WebSocketConfig {
+	return WebSocketConfig{
+		MaxConnections:   10,
+		MaxSubscriptions: 2,
+		PingInterval:     time.Hour,
+		PongTimeout:      time.Hour,
+		WriteTimeout:     time.Second,
+	}
+}
+
+func dialWebSocket(t *testing.T, srv *httptest.Server, header http.Header) *websocket.Conn {
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", header)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func request(t *testing.T, conn *websocket.Conn, req wsRequest) wsMessage {
+	require.NoError(t, conn.WriteJSON(req))
+	return receive(t, conn)
+}
+
+func receive(t *testing.T, conn *websocket.Conn) wsMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var msg wsMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func priced(c productevent.Cents) *productevent.Cents {
+	return &c
+}
+
+func TestWebSocketHandlerSubscriptions(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+	srv := newWebSocketServer(t, hub, testWebSocketConfig())
+	conn := dialWebSocket(t, srv, nil)
+
+	assert.Equal(t, wsMessage{Type: "subscribed", ID: "expensive"},
+		request(t, conn, wsRequest{Action: "subscribe", ID: "expensive", Filter: wsFilter{MinPrice: priced(10000)}}))
+	assert.Equal(t, wsMessage{Type: "subscribed", ID: "p1"},
+		request(t, conn, wsRequest{Action: "subscribe", ID: "p1", Filter: wsFilter{Types: []string{"product_deleted"}, ProductIDs: []string{"p1"}}}))
+
+	cheap := productevent.New(productevent.Created, productevent.Product{ID: "p2", Price: 500}, productevent.Metadata{TenantID: "acme"})
+	otherTenant := productevent.New(productevent.Created, productevent.Product{ID: "p3", Price: 50000}, productevent.Metadata{TenantID: "globex"})
+	deleted := productevent.New(productevent.Deleted, productevent.Product{ID: "p1", Price: 20000}, productevent.Metadata{TenantID: "acme"})
+	hub.Publish(cheap)
+	hub.Publish(otherTenant)
+	hub.Publish(deleted)
+
+	msg := receive(t, conn)
+	assert.Equal(t, "event", msg.Type)
+	assert.Equal(t, []string{"expensive", "p1"}, msg.Subscriptions, "An event is sent once with every matching subscription")
+	decoded, err := productevent.DecodeJSON(msg.Event)
+	require.NoError(t, err)
+	assert.Equal(t, deleted.ID, decoded.ID)
+
+	assert.Equal(t, wsMessage{Type: "unsubscribed", ID: "expensive"}, request(t, conn, wsRequest{Action: "unsubscribe", ID: "expensive"}))
+
+	hub.Publish(productevent.New(productevent.Created, productevent.Product{ID: "p4", Price: 20000}, productevent.Metadata{TenantID: "acme"}))
+	replaced := request(t, conn, wsRequest{Action: "subscribe", ID: "p1", Filter: wsFilter{ProductIDs: []string{"p5"}}})
+	assert.Equal(t, wsMessage{Type: "subscribed", ID: "p1"}, replaced, "No event should arrive after unsubscribing")
+
+	created := productevent.New(productevent.Created, productevent.Product{ID: "p5", Price: 100}, productevent.Metadata{TenantID: "acme"})
+	hub.Publish(created)
+
+	msg = receive(t, conn)
+	assert.Equal(t, []string{"p1"}, msg.Subscriptions)
+	assert.Contains(t, string(msg.Event), created.ID)
+}
+
+func TestWebSocketHandlerInvalidRequests(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+	srv := newWebSocketServer(t, hub, testWebSocketConfig())
+	conn := dialWebSocket(t, srv, nil)
+
+	type testCase struct {
+		name    string
+		message string
+		error   string
+	}
+
+	cases := []testCase{
+		{name: "Malformed", message: `{"action":`, error: "invalid message"},
+		{name: "Missing ID", message: `{"action":"subscribe"}`, error: "id is required"},
+		{name: "Unknown Action", message: `{"action":"publish","id":"a"}`, error: "unknown action"},
+		{name: "Unknown Subscription", message: `{"action":"unsubscribe","id":"a"}`, error: "unknown subscription"},
+		{name: "Inverted Prices", message: `{"action":"subscribe","id":"a","filter":{"min_price":200,"max_price":100}}`, error: "min_price"},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(tCase.message)))
+			msg := receive(t, conn)
+			assert.Equal(t, "error", msg.Type)
+			assert.Contains(t, msg.Error, tCase.error)
+		})
+	}
+
+	t.Run("Subscription Limit", func(t *testing.T) {
+		request(t, conn, wsRequest{Action: "subscribe", ID: "a"})
+		request(t, conn, wsRequest{Action: "subscribe", ID: "b"})
+
+		msg := request(t, conn, wsRequest{Action: "subscribe", ID: "c"})
+		assert.Equal(t, "error", msg.Type)
+		assert.Equal(t, "subscribed", request(t, conn, wsRequest{Action: "subscribe", ID: "a"}).Type, "Replacing a subscription isn't limited")
+	})
+}
+
+func TestWebSocketHandlerConnectionLimit(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+	cfg := testWebSocketConfig()
+	cfg.MaxConnections = 1
+	srv := newWebSocketServer(t, hub, cfg)
+	dialWebSocket(t, srv, nil)
+
+	rejectedBefore := testutil.ToFloat64(metrics.WebSocketConnectionsRejected)
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, rejectedBefore+1, testutil.ToFloat64(metrics.WebSocketConnectionsRejected))
+}
+
+func TestWebSocketHandlerOrigin(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+	cfg := testWebSocketConfig()
+	cfg.AllowedOrigins = []string{"https://admin.example.com"}
+	srv := newWebSocketServer(t, hub, cfg)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	dialWebSocket(t, srv, http.Header{"Origin": {"https://admin.example.com"}})
+	dialWebSocket(t, srv, http.Header{"Origin": {srv.URL}})
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestWebSocketHandlerKeepalive(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+	cfg := testWebSocketConfig()
+	cfg.PingInterval = 10 * time.Millisecond
+	cfg.PongTimeout = 100 * time.Millisecond
+	srv := newWebSocketServer(t, hub, cfg)
+
+	t.Run("Pings", func(t *testing.T) {
+		conn := dialWebSocket(t, srv, nil)
+		pinged := make(chan struct{}, 1)
+		conn.SetPingHandler(func(string) error {
+			select {
+			case pinged <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		select {
+		case <-pinged:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no ping received")
+		}
+	})
+
+	t.Run("Closes Silent Connection", func(t *testing.T) {
+		conn := dialWebSocket(t, srv, nil)
+		// Without a read loop the client never answers pings.
+		time.Sleep(300 * time.Millisecond)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		conn.SetPingHandler(func(string) error { return nil })
+		var err error
+		for err == nil {
+			_, _, err = conn.ReadMessage()
+		}
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseAbnormalClosure), "The server should drop the connection, got %v", err)
+	})
+}
+
+func TestWebSocketHandlerClosesOnHubClose(t *testing.T) {
+	hub := stream.NewHub(stream.Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	srv := newWebSocketServer(t, hub, testWebSocketConfig())
+	conn := dialWebSocket(t, srv, nil)
+	request(t, conn, wsRequest{Action: "subscribe", ID: "all"})
+
+	hub.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error %v", err)
+}
@@ -1,6 +1,10 @@
 package logger
 
 import (
+	"context"
+	"products/internal/requestid"
+
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -34,3 +38,19 @@ func NewLogger(environment string, level zapcore.Level) *zap.Logger {
 func NewSugaredLogger(environment string, level zapcore.Level) *zap.SugaredLogger {
 	return NewLogger(environment, level).Sugar()
 }
+
+// WithContext adds the request scoped fields found in ctx to the logger.
+func WithContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	var fields []zap.Field
+	if id, ok := requestid.FromContext(ctx); ok {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String()))
+	}
+
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
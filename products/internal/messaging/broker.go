@@ -0,0 +1,101 @@
+package messaging
+
+import (
+	"context"
+	"contracts/membroker"
+	"fmt"
+	"maps"
+	"products/internal/models"
+	"products/internal/requestid"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("products/internal/messaging")
+
+const (
+	// TypeKafka publishes to Kafka, it needs a cgo build with librdkafka.
+	TypeKafka = "kafka"
+	// TypeMemory keeps messages in process, for local development and tests.
+	TypeMemory = "memory"
+	// TypeNATS publishes to a NATS JetStream stream.
+	TypeNATS = "nats"
+)
+
+const (
+	// DeliveryAsync returns from Send as soon as the message is queued.
+	DeliveryAsync = "async"
+	// DeliverySync makes Send wait for the delivery report and return its error.
+	DeliverySync = "sync"
+)
+
+// ProducerHeader names the service that produced a message.
+const ProducerHeader = "producer"
+
+// DeliveryHandler is called with the outcome of every produced message.
+type DeliveryHandler func(report models.DeliveryReport)
+
+// Broker is a message broker backend.
+type Broker interface {
+	Send(ctx context.Context, topic string, message, key []byte, headers map[string]string) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// DeliveryReporter is implemented by brokers that report the outcome of sent
+// messages after Send returns.
+type DeliveryReporter interface {
+	SetDeliveryHandler(fn DeliveryHandler)
+}
+
+type Config struct {
+	// Type is TypeKafka (the default), TypeMemory or TypeNATS.
+	Type         string
+	Endpoint     string
+	BaseClientID string
+	Topic        string
+	// Stream is the JetStream stream capturing Topic, only used by TypeNATS.
+	Stream string
+	// DeliveryMode is DeliveryAsync (the default) or DeliverySync.
+	DeliveryMode string
+	// StatisticsInterval enables librdkafka statistics, 0 disables them.
+	StatisticsInterval time.Duration
+}
+
+// withContextHeaders returns a copy of headers with the producer, the request
+// id and the trace context of ctx added. The trace context of ctx replaces one
+// captured in headers.
+func withContextHeaders(ctx context.Context, producer string, headers map[string]string) map[string]string {
+	result := make(map[string]string, len(headers)+4)
+	maps.Copy(result, headers)
+	if _, ok := result[ProducerHeader]; !ok && producer != "" {
+		result[ProducerHeader] = producer
+	}
+	if id, ok := requestid.FromContext(ctx); ok {
+		result[requestid.MessageHeader] = id
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(result))
+	return result
+}
+
+// NewBroker creates the broker of type cfg.Type.
+func NewBroker(cfg Config, logger *zap.Logger) (Broker, error) {
+	switch cfg.Type {
+	case "", TypeKafka:
+		return newKafkaBroker(cfg, logger)
+	case TypeMemory:
+		logger.Named("MessageBroker").Warn("Using the in-memory message broker, messages don't leave the process")
+		return NewMemoryBroker(membroker.New(membroker.DefaultPartitions), cfg), nil
+	case TypeNATS:
+		broker, err := NewNATSBroker(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return broker, nil
+	default:
+		return nil, fmt.Errorf("unknown message broker type %q", cfg.Type)
+	}
+}
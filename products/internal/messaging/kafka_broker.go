@@ -1,26 +1,36 @@
+//go:build cgo
+
 package messaging
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	loggerPkg "products/internal/logger"
+	"products/internal/metrics"
+	"products/internal/models"
+	"products/internal/requestid"
+	"slices"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gopkg.in/confluentinc/confluent-kafka-go.v1/kafka"
 )
 
 type KafkaBroker struct {
-	producer *kafka.Producer
-	topic    string
-	config   *kafka.ConfigMap
-	logger   *zap.Logger
-}
-
-type Config struct {
-	Endpoint     string
-	BaseClientID string
-	Topic        string
+	producer     *kafka.Producer
+	topic        string
+	producerName string
+	deliveryMode string
+	config       *kafka.ConfigMap
+	onDelivery   atomic.Pointer[DeliveryHandler]
+	logger       *zap.Logger
 }
 
 func NewKafkaBroker(cfg Config, logger *zap.Logger) (*KafkaBroker, error) {
@@ -30,6 +40,13 @@ func NewKafkaBroker(cfg Config, logger *zap.Logger) (*KafkaBroker, error) {
 	if cfg.Topic == "" {
 		return nil, fmt.Errorf("kafka topic is required")
 	}
+	switch cfg.DeliveryMode {
+	case "":
+		cfg.DeliveryMode = DeliveryAsync
+	case DeliveryAsync, DeliverySync:
+	default:
+		return nil, fmt.Errorf("unknown kafka delivery mode %q", cfg.DeliveryMode)
+	}
 
 	id := cfg.BaseClientID
 	if h, err := os.Hostname(); err == nil {
@@ -43,6 +60,9 @@ func NewKafkaBroker(cfg Config, logger *zap.Logger) (*KafkaBroker, error) {
 		"acks":               "all",
 		"enable.idempotence": true,
 	}
+	if cfg.StatisticsInterval > 0 {
+		_ = config.SetKey("statistics.interval.ms", int(cfg.StatisticsInterval.Milliseconds()))
+	}
 
 	producer, err := kafka.NewProducer(config)
 	if err != nil {
@@ -50,10 +70,12 @@ func NewKafkaBroker(cfg Config, logger *zap.Logger) (*KafkaBroker, error) {
 	}
 
 	broker := &KafkaBroker{
-		producer: producer,
-		topic:    cfg.Topic,
-		config:   config,
-		logger:   logger.Named("KafkaBroker"),
+		producer:     producer,
+		topic:        cfg.Topic,
+		producerName: cfg.BaseClientID,
+		deliveryMode: cfg.DeliveryMode,
+		config:       config,
+		logger:       logger.Named("KafkaBroker"),
 	}
 
 	go broker.handleDeliveryReports()
@@ -61,7 +83,9 @@ func NewKafkaBroker(cfg Config, logger *zap.Logger) (*KafkaBroker, error) {
 	return broker, nil
 }
 
-func (b *KafkaBroker) Send(ctx context.Context, topic string, message, key []byte) error {
+// Send produces message with headers, adding the producer, request id and
+// trace context headers.
+func (b *KafkaBroker) Send(ctx context.Context, topic string, message, key []byte, headers map[string]string) error {
 	if b.producer == nil {
 		return fmt.Errorf("kafka producer is not initialized")
 	}
@@ -71,6 +95,7 @@ func (b *KafkaBroker) Send(ctx context.Context, topic string, message, key []byt
 		targetTopic = topic
 	}
 
+	now := time.Now()
 	kafkaMessage := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{
 			Topic:     &targetTopic,
@@ -78,15 +103,105 @@ func (b *KafkaBroker) Send(ctx context.Context, topic string, message, key []byt
 		},
 		Value:     message,
 		Key:       key,
-		Timestamp: time.Now(),
+		Timestamp: now,
+		// Opaque carries the send time to the delivery report for latency tracking.
+		Opaque: now,
 	}
 
-	deliveryChan := make(chan kafka.Event, 1)
+	ctx, span := tracer.Start(ctx, targetTopic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(targetTopic),
+			semconv.MessagingKafkaMessageKey(string(key)),
+		),
+	)
+	defer span.End()
+
+	kafkaMessage.Headers = b.messageHeaders(ctx, headers)
+
+	// In async mode delivery reports go to the producer's events channel, see handleDeliveryReports.
+	var deliveryChan chan kafka.Event
+	if b.deliveryMode == DeliverySync {
+		deliveryChan = make(chan kafka.Event, 1)
+	}
 
 	err := b.producer.Produce(kafkaMessage, deliveryChan)
 	if err != nil {
+		metrics.KafkaMessagesFailed.WithLabelValues(targetTopic, errorCode(err)).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		loggerPkg.WithContext(ctx, b.logger).Error("Failed to produce message:", zap.Error(err), zap.String("topic", targetTopic))
 		return fmt.Errorf("failed to produce message: %w", err)
 	}
+	metrics.KafkaMessagesProduced.WithLabelValues(targetTopic).Inc()
+
+	if deliveryChan == nil {
+		return nil
+	}
+
+	select {
+	case e := <-deliveryChan:
+		if err := b.processDelivery(e.(*kafka.Message)); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to deliver message: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		// The report still has to be accounted for once it arrives.
+		go func() {
+			_ = b.processDelivery((<-deliveryChan).(*kafka.Message))
+		}()
+		span.RecordError(ctx.Err())
+		span.SetStatus(codes.Error, ctx.Err().Error())
+		return fmt.Errorf("failed to wait for delivery report: %w", ctx.Err())
+	}
+}
+
+// messageHeaders converts the headers of a message to Kafka headers in a stable order.
+func (b *KafkaBroker) messageHeaders(ctx context.Context, headers map[string]string) []kafka.Header {
+	headers = withContextHeaders(ctx, b.producerName, headers)
+	kafkaHeaders := make([]kafka.Header, 0, len(headers))
+	for _, key := range slices.Sorted(maps.Keys(headers)) {
+		kafkaHeaders = append(kafkaHeaders, kafka.Header{Key: key, Value: []byte(headers[key])})
+	}
+	return kafkaHeaders
+}
+
+// SetDeliveryHandler registers fn to be called with the outcome of every
+// produced message, in both delivery modes.
+func (b *KafkaBroker) SetDeliveryHandler(fn DeliveryHandler) {
+	b.onDelivery.Store(&fn)
+}
+
+// Ping checks that the cluster is reachable and knows the default topic.
+func (b *KafkaBroker) Ping(ctx context.Context) error {
+	if b.producer == nil {
+		return fmt.Errorf("kafka producer is not initialized")
+	}
+
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if timeout <= 0 {
+		return context.DeadlineExceeded
+	}
+
+	metadata, err := b.producer.GetMetadata(&b.topic, false, int(timeout.Milliseconds()))
+	if err != nil {
+		return fmt.Errorf("failed to get metadata: %w", err)
+	}
+
+	topic, ok := metadata.Topics[b.topic]
+	if !ok {
+		return fmt.Errorf("topic %s not found", b.topic)
+	}
+	if topic.Error.Code() != kafka.ErrNoError {
+		return fmt.Errorf("topic %s: %w", b.topic, topic.Error)
+	}
 
 	return nil
 }
@@ -99,13 +214,78 @@ func (b *KafkaBroker) handleDeliveryReports() {
 	for e := range b.producer.Events() {
 		switch ev := e.(type) {
 		case *kafka.Message:
-			if ev.TopicPartition.Error != nil {
-				b.logger.Error("Failed to deliver message:", zap.Error(ev.TopicPartition.Error))
+			_ = b.processDelivery(ev)
+		case *kafka.Stats:
+			if err := recordStats(ev.String()); err != nil {
+				b.logger.Warn("Failed to parse Kafka statistics:", zap.Error(err))
 			}
 		case kafka.Error:
 			b.logger.Error("Kafka error:", zap.Error(ev))
 		}
+		metrics.KafkaProducerQueueLength.Set(float64(b.producer.Len()))
+	}
+}
+
+// processDelivery records the outcome of a delivery report, passes it to the
+// delivery handler and returns the delivery error.
+func (b *KafkaBroker) processDelivery(msg *kafka.Message) error {
+	report := models.DeliveryReport{
+		Key:       msg.Key,
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+		RequestID: headerValue(msg.Headers, requestid.MessageHeader),
+		Err:       msg.TopicPartition.Error,
+	}
+	if msg.TopicPartition.Topic != nil {
+		report.Topic = *msg.TopicPartition.Topic
+	}
+	if sentAt, ok := msg.Opaque.(time.Time); ok {
+		report.Latency = time.Since(sentAt)
+		metrics.KafkaDeliveryLatency.WithLabelValues(report.Topic).Observe(report.Latency.Seconds())
+	}
+
+	if report.Err != nil {
+		metrics.KafkaMessagesFailed.WithLabelValues(report.Topic, errorCode(report.Err)).Inc()
+		b.logger.Error("Failed to deliver message:",
+			zap.Error(report.Err),
+			zap.String("topic", report.Topic),
+			zap.String("request_id", report.RequestID),
+		)
+	} else {
+		metrics.KafkaMessagesDelivered.WithLabelValues(report.Topic).Inc()
+	}
+
+	if fn := b.onDelivery.Load(); fn != nil {
+		(*fn)(report)
 	}
+
+	return report.Err
+}
+
+// errorCode returns the librdkafka error code name used as a metric label.
+func errorCode(err error) string {
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr.Code().String()
+	}
+	return "unknown"
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func newKafkaBroker(cfg Config, logger *zap.Logger) (Broker, error) {
+	broker, err := NewKafkaBroker(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return broker, nil
 }
 
 func (b *KafkaBroker) Close() error {
@@ -0,0 +1,89 @@
+//go:build cgo
+
+package messaging
+
+import (
+	"context"
+	"products/internal/models"
+	"products/internal/requestid"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/confluentinc/confluent-kafka-go.v1/kafka"
+)
+
+func TestProcessDelivery(t *testing.T) {
+	broker := &KafkaBroker{logger: zap.NewNop()}
+
+	var reports []models.DeliveryReport
+	broker.SetDeliveryHandler(func(report models.DeliveryReport) {
+		reports = append(reports, report)
+	})
+
+	topic := "product-events"
+	message := func(err error) *kafka.Message {
+		return &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 2, Offset: 42, Error: err},
+			Key:            []byte("uuid-1"),
+			Headers:        []kafka.Header{{Key: "request-id", Value: []byte("req-1")}},
+			Opaque:         time.Now().Add(-time.Second),
+		}
+	}
+
+	t.Run("Delivered", func(t *testing.T) {
+		reports = nil
+
+		err := broker.processDelivery(message(nil))
+
+		assert.NoError(t, err)
+		require.Len(t, reports, 1)
+		assert.Equal(t, "product-events", reports[0].Topic)
+		assert.Equal(t, []byte("uuid-1"), reports[0].Key)
+		assert.Equal(t, int32(2), reports[0].Partition)
+		assert.Equal(t, int64(42), reports[0].Offset)
+		assert.Equal(t, "req-1", reports[0].RequestID)
+		assert.GreaterOrEqual(t, reports[0].Latency, time.Second)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		reports = nil
+		deliveryErr := kafka.NewError(kafka.ErrMsgTimedOut, "message timed out", false)
+
+		err := broker.processDelivery(message(deliveryErr))
+
+		assert.Equal(t, deliveryErr, err)
+		require.Len(t, reports, 1)
+		assert.Equal(t, deliveryErr, reports[0].Err)
+	})
+}
+
+func TestMessageHeaders(t *testing.T) {
+	broker := &KafkaBroker{producerName: "product-service", logger: zap.NewNop()}
+	ctx := requestid.NewContext(context.Background(), "req-2")
+
+	headers := broker.messageHeaders(ctx, map[string]string{
+		"event-type":     "products.product_created",
+		"content-type":   "application/cloudevents+json",
+		"schema-version": "1",
+		"request-id":     "req-1",
+	})
+
+	assert.Equal(t, []kafka.Header{
+		{Key: "content-type", Value: []byte("application/cloudevents+json")},
+		{Key: "event-type", Value: []byte("products.product_created")},
+		{Key: "producer", Value: []byte("product-service")},
+		{Key: "request-id", Value: []byte("req-2")},
+		{Key: "schema-version", Value: []byte("1")},
+	}, headers, "The request id of ctx should win and no header should be duplicated")
+
+	headers = broker.messageHeaders(context.Background(), map[string]string{"producer": "importer"})
+	assert.Equal(t, []kafka.Header{{Key: "producer", Value: []byte("importer")}}, headers)
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, kafka.ErrQueueFull.String(), errorCode(kafka.NewError(kafka.ErrQueueFull, "queue full", false)))
+	assert.Equal(t, "unknown", errorCode(assert.AnError))
+}
@@ -0,0 +1,30 @@
+package messaging
+
+import (
+	"context"
+	"contracts/membroker"
+)
+
+// MemoryBroker publishes to an in-process broker, which consumers in the same
+// process can share.
+type MemoryBroker struct {
+	*membroker.Broker
+	topic        string
+	producerName string
+}
+
+func NewMemoryBroker(broker *membroker.Broker, cfg Config) *MemoryBroker {
+	return &MemoryBroker{
+		Broker:       broker,
+		topic:        cfg.Topic,
+		producerName: cfg.BaseClientID,
+	}
+}
+
+// Send appends message to topic, the configured topic when it's empty.
+func (b *MemoryBroker) Send(ctx context.Context, topic string, message, key []byte, headers map[string]string) error {
+	if topic == "" {
+		topic = b.topic
+	}
+	return b.Broker.Send(ctx, topic, message, key, withContextHeaders(ctx, b.producerName, headers))
+}
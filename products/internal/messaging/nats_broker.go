@@ -0,0 +1,139 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	loggerPkg "products/internal/logger"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// KeyHeader carries the message key on brokers without native keys, such as NATS.
+const KeyHeader = "message-key"
+
+// NATSBroker publishes to a JetStream stream, topics are subjects of the stream.
+// Send waits for the stream to acknowledge the message, so it is always synchronous.
+type NATSBroker struct {
+	conn         *nats.Conn
+	js           jetstream.JetStream
+	stream       string
+	topic        string
+	producerName string
+	logger       *zap.Logger
+}
+
+func NewNATSBroker(cfg Config, logger *zap.Logger) (*NATSBroker, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("nats endpoint is required")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("nats topic is required")
+	}
+	if cfg.Stream == "" {
+		return nil, fmt.Errorf("nats stream is required")
+	}
+
+	conn, err := nats.Connect(cfg.Endpoint, nats.Name(cfg.BaseClientID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := ensureStream(ctx, js, cfg.Stream, cfg.Topic); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &NATSBroker{
+		conn:         conn,
+		js:           js,
+		stream:       cfg.Stream,
+		topic:        cfg.Topic,
+		producerName: cfg.BaseClientID,
+		logger:       logger.Named("NATSBroker"),
+	}, nil
+}
+
+// ensureStream creates the stream capturing topic unless it already exists.
+// An existing stream is left as is, its configuration is owned by whoever created it.
+func ensureStream(ctx context.Context, js jetstream.JetStream, stream, topic string) error {
+	_, err := js.Stream(ctx, stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("failed to look up stream %s: %w", stream, err)
+	}
+
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     stream,
+		Subjects: []string{topic},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("failed to create stream %s: %w", stream, err)
+	}
+	return nil
+}
+
+// Send publishes message with headers to topic, the configured topic when it's
+// empty, adding the key, producer, request id and trace context headers.
+func (b *NATSBroker) Send(ctx context.Context, topic string, message, key []byte, headers map[string]string) error {
+	if topic == "" {
+		topic = b.topic
+	}
+
+	ctx, span := tracer.Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(TypeNATS),
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(topic),
+		),
+	)
+	defer span.End()
+
+	msg := nats.NewMsg(topic)
+	msg.Data = message
+	for k, v := range withContextHeaders(ctx, b.producerName, headers) {
+		msg.Header.Set(k, v)
+	}
+	if len(key) > 0 {
+		msg.Header.Set(KeyHeader, string(key))
+	}
+
+	if _, err := b.js.PublishMsg(ctx, msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		loggerPkg.WithContext(ctx, b.logger).Error("Failed to publish message", zap.Error(err), zap.String("topic", topic))
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// Ping checks that the server is reachable and has the stream.
+func (b *NATSBroker) Ping(ctx context.Context) error {
+	if _, err := b.js.Stream(ctx, b.stream); err != nil {
+		return fmt.Errorf("failed to get stream %s: %w", b.stream, err)
+	}
+	return nil
+}
+
+// Close waits for pending publishes and closes the connection.
+func (b *NATSBroker) Close() error {
+	return b.conn.Drain()
+}
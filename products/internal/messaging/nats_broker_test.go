@@ -0,0 +1,93 @@
+package messaging
+
+import (
+	"context"
+	"products/internal/requestid"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// runNATSServer starts an embedded JetStream enabled server and returns its URL.
+func runNATSServer(t *testing.T) string {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server didn't start")
+	}
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+	return srv.ClientURL()
+}
+
+func TestNATSBroker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	url := runNATSServer(t)
+	broker, err := NewBroker(Config{
+		Type:         TypeNATS,
+		Endpoint:     url,
+		BaseClientID: "product-service",
+		Topic:        "product-events",
+		Stream:       "PRODUCT_EVENTS",
+	}, zap.NewNop())
+	require.NoError(t, err)
+	defer broker.Close()
+
+	require.NoError(t, broker.Ping(ctx))
+
+	ctx = requestid.NewContext(ctx, "req-1")
+	require.NoError(t, broker.Send(ctx, "", []byte("created"), []byte("42"), map[string]string{"event-type": "products.product_created"}))
+	require.NoError(t, broker.Send(ctx, "product-events", []byte("deleted"), nil, nil))
+
+	reused, err := NewNATSBroker(Config{Endpoint: url, Topic: "product-events", Stream: "PRODUCT_EVENTS"}, zap.NewNop())
+	require.NoError(t, err, "An existing stream should be reused")
+	require.NoError(t, reused.Close())
+
+	conn, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	stream, err := js.Stream(ctx, "PRODUCT_EVENTS")
+	require.NoError(t, err)
+
+	first, err := stream.GetMsg(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "product-events", first.Subject)
+	assert.Equal(t, []byte("created"), first.Data)
+	assert.Equal(t, "42", first.Header.Get(KeyHeader))
+	assert.Equal(t, "products.product_created", first.Header.Get("event-type"))
+	assert.Equal(t, "product-service", first.Header.Get(ProducerHeader))
+	assert.Equal(t, "req-1", first.Header.Get(requestid.MessageHeader))
+
+	second, err := stream.GetMsg(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("deleted"), second.Data)
+	assert.Empty(t, second.Header.Get(KeyHeader))
+
+	t.Run("Unknown Topic", func(t *testing.T) {
+		err := broker.Send(ctx, "order-events", []byte("created"), nil, nil)
+		assert.Error(t, err, "Only subjects of the stream can be published to")
+	})
+}
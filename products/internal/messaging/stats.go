@@ -0,0 +1,53 @@
+package messaging
+
+import (
+	"encoding/json"
+	"products/internal/metrics"
+)
+
+// stats is the subset of the librdkafka statistics document we export,
+// see https://github.com/confluentinc/librdkafka/blob/master/STATISTICS.md.
+type stats struct {
+	MsgCnt     int64 `json:"msg_cnt"`
+	MsgSize    int64 `json:"msg_size"`
+	TxMsgs     int64 `json:"txmsgs"`
+	TxMsgBytes int64 `json:"txmsg_bytes"`
+	Brokers    map[string]struct {
+		Name         string `json:"name"`
+		NodeID       int32  `json:"nodeid"`
+		OutbufMsgCnt int64  `json:"outbuf_msg_cnt"`
+		TxErrs       int64  `json:"txerrs"`
+		TxRetries    int64  `json:"txretries"`
+		RTT          struct {
+			Avg int64 `json:"avg"`
+			P99 int64 `json:"p99"`
+		} `json:"rtt"`
+	} `json:"brokers"`
+}
+
+func recordStats(data string) error {
+	var s stats
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return err
+	}
+
+	metrics.KafkaStatsMessages.Set(float64(s.MsgCnt))
+	metrics.KafkaStatsMessagesBytes.Set(float64(s.MsgSize))
+	metrics.KafkaStatsTxMessages.Set(float64(s.TxMsgs))
+	metrics.KafkaStatsTxBytes.Set(float64(s.TxMsgBytes))
+
+	for name, broker := range s.Brokers {
+		// Bootstrap brokers are replaced by the real ones once metadata is known.
+		if broker.NodeID < 0 {
+			continue
+		}
+		// librdkafka reports rtt in microseconds.
+		metrics.KafkaStatsBrokerRTT.WithLabelValues(name, "avg").Set(float64(broker.RTT.Avg) / 1e6)
+		metrics.KafkaStatsBrokerRTT.WithLabelValues(name, "p99").Set(float64(broker.RTT.P99) / 1e6)
+		metrics.KafkaStatsBrokerOutbufMessages.WithLabelValues(name).Set(float64(broker.OutbufMsgCnt))
+		metrics.KafkaStatsBrokerTxErrors.WithLabelValues(name).Set(float64(broker.TxErrs))
+		metrics.KafkaStatsBrokerTxRetries.WithLabelValues(name).Set(float64(broker.TxRetries))
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package messaging
+
+import (
+	"products/internal/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordStats(t *testing.T) {
+	data := `{
+		"name": "product-service#producer-1",
+		"type": "producer",
+		"msg_cnt": 3,
+		"msg_size": 1024,
+		"txmsgs": 120,
+		"txmsg_bytes": 40960,
+		"brokers": {
+			"localhost:9092/bootstrap": {"name": "localhost:9092/bootstrap", "nodeid": -1, "rtt": {"avg": 0, "p99": 0}},
+			"kafka:9092/1": {
+				"name": "kafka:9092/1",
+				"nodeid": 1,
+				"outbuf_msg_cnt": 2,
+				"txerrs": 1,
+				"txretries": 4,
+				"rtt": {"avg": 1500, "p99": 25000}
+			}
+		}
+	}`
+
+	require.NoError(t, recordStats(data))
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.KafkaStatsMessages))
+	assert.Equal(t, 1024.0, testutil.ToFloat64(metrics.KafkaStatsMessagesBytes))
+	assert.Equal(t, 120.0, testutil.ToFloat64(metrics.KafkaStatsTxMessages))
+	assert.Equal(t, 0.0015, testutil.ToFloat64(metrics.KafkaStatsBrokerRTT.WithLabelValues("kafka:9092/1", "avg")))
+	assert.Equal(t, 0.025, testutil.ToFloat64(metrics.KafkaStatsBrokerRTT.WithLabelValues("kafka:9092/1", "p99")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.KafkaStatsBrokerOutbufMessages.WithLabelValues("kafka:9092/1")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.KafkaStatsBrokerTxErrors), "Bootstrap brokers should be skipped")
+
+	assert.Error(t, recordStats("not json"))
+}
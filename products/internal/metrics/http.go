@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Routes are labeled by their template (e.g. /products/:id), not the raw path,
+// to keep the number of series bounded.
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests",
+	}, []string{"route", "method", "status_class"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status_class"})
+
+	HTTPRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served",
+	})
+
+	HTTPRequestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_size_bytes",
+		Help:    "HTTP request body size",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, []string{"route", "method"})
+
+	HTTPResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_response_size_bytes",
+		Help:    "HTTP response body size",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, []string{"route", "method"})
+)
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	KafkaMessagesProduced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_producer_messages_produced_total",
+		Help: "Total number of messages handed to the Kafka producer",
+	}, []string{"topic"})
+
+	KafkaMessagesDelivered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_producer_messages_delivered_total",
+		Help: "Total number of messages acknowledged by the Kafka cluster",
+	}, []string{"topic"})
+
+	KafkaMessagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_producer_messages_failed_total",
+		Help: "Total number of messages that could not be produced or delivered",
+	}, []string{"topic", "error_code"})
+
+	KafkaDeliveryLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_producer_delivery_latency_seconds",
+		Help:    "Time from producing a message to receiving its delivery report",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"topic"})
+
+	KafkaProducerQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kafka_producer_queue_length",
+		Help: "Number of messages and requests waiting in the librdkafka outbound queue",
+	})
+)
+
+// Gauges fed from librdkafka statistics (statistics.interval.ms). Totals are
+// counted by librdkafka since the client started.
+var (
+	KafkaStatsMessages = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_queue_messages",
+		Help: "Number of messages in the producer queues",
+	})
+
+	KafkaStatsMessagesBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_queue_bytes",
+		Help: "Size of messages in the producer queues",
+	})
+
+	KafkaStatsTxMessages = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_tx_messages",
+		Help: "Total number of messages transmitted to brokers",
+	})
+
+	KafkaStatsTxBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_tx_bytes",
+		Help: "Total number of message bytes transmitted to brokers",
+	})
+
+	KafkaStatsBrokerRTT = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_broker_rtt_seconds",
+		Help: "Broker round trip time over the last statistics window",
+	}, []string{"broker", "quantile"})
+
+	KafkaStatsBrokerOutbufMessages = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_broker_outbuf_messages",
+		Help: "Number of messages waiting to be sent to a broker",
+	}, []string{"broker"})
+
+	KafkaStatsBrokerTxErrors = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_broker_tx_errors",
+		Help: "Total number of transmission errors per broker",
+	}, []string{"broker"})
+
+	KafkaStatsBrokerTxRetries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_producer_stats_broker_tx_retries",
+		Help: "Total number of request retries per broker",
+	}, []string{"broker"})
+)
@@ -0,0 +1,13 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	LeaderElected = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leader_elected",
+		Help: "1 when this instance is the leader of the election, 0 otherwise",
+	}, []string{"election"})
+)
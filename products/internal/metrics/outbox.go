@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	OutboxPending = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_pending_messages",
+		Help: "Number of outbox messages waiting to be published",
+	})
+
+	OutboxOldestAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_oldest_pending_age_seconds",
+		Help: "Age of the oldest outbox message waiting to be published",
+	})
+
+	OutboxPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_published_total",
+		Help: "Total number of outbox messages published",
+	})
+
+	OutboxPublishFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_publish_failures_total",
+		Help: "Total number of failed outbox publish attempts",
+	})
+
+	OutboxDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_deleted_total",
+		Help: "Total number of sent outbox messages cleaned up",
+	})
+)
@@ -15,4 +15,9 @@ var (
 		Name: "products_deleted_total",
 		Help: "Total number of deleted products",
 	})
+
+	ProductEventsUndelivered = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "product_events_undelivered_total",
+		Help: "Total number of product events rejected by the message broker",
+	})
 )
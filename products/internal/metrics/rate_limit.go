@@ -0,0 +1,13 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	RateLimitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_requests_total",
+		Help: "Total number of requests rejected by the rate limiter",
+	}, []string{"class"})
+)
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	StreamClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "product_stream_clients",
+		Help: "Number of clients connected to the product event stream",
+	})
+
+	StreamClientsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "product_stream_clients_dropped_total",
+		Help: "Total number of stream clients disconnected for falling behind",
+	})
+
+	WebSocketConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "product_websocket_connections",
+		Help: "Number of open product event WebSocket connections",
+	})
+
+	WebSocketConnectionsRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "product_websocket_connections_rejected_total",
+		Help: "Total number of WebSocket connections rejected by the connection limit",
+	})
+)
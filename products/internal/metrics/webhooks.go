@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// WebhookDeliveryAttempts is labeled with the outcome: delivered, retry or failed.
+	WebhookDeliveryAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_delivery_attempts_total",
+		Help: "Total number of webhook delivery attempts by outcome",
+	}, []string{"outcome"})
+
+	WebhookDeliveryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "webhook_delivery_duration_seconds",
+		Help:    "Duration of webhook delivery requests",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	WebhookSubscriptionsDisabled = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_subscriptions_disabled_total",
+		Help: "Total number of webhook subscriptions disabled after consecutive failures",
+	})
+
+	WebhookDeliveriesDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_deliveries_deleted_total",
+		Help: "Total number of finished webhook deliveries cleaned up",
+	})
+)
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"products/internal/apperrors"
+	"products/internal/auth"
+	loggerPkg "products/internal/logger"
+	"products/internal/metrics"
+	"products/internal/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, rawToken string) (*auth.Principal, error)
+}
+
+// RouteRule declares who may call a route. Admins may call every route.
+type RouteRule struct {
+	Public bool
+	Roles  []string
+}
+
+// RoutePolicy maps "METHOD /route/template" (as reported by gin's FullPath) to its rule.
+type RoutePolicy map[string]RouteRule
+
+// Authenticate resolves the caller from a bearer JWT or an API key and stores the
+// principal in the request context. Requests without credentials continue anonymously,
+// Authorize decides whether the route allows that. tokens may be nil when JWTs are disabled.
+func Authenticate(tokens TokenVerifier, apiKeys APIKeyAuthenticator, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("Authenticate")
+
+	return func(c *gin.Context) {
+		var (
+			principal *auth.Principal
+			reason    string
+			err       error
+		)
+
+		if header := c.GetHeader("Authorization"); header != "" {
+			principal, reason, err = authenticateBearer(c.Request.Context(), tokens, header)
+		} else if rawKey := c.GetHeader(APIKeyHeader); rawKey != "" {
+			principal, reason, err = authenticateAPIKey(c.Request.Context(), apiKeys, rawKey)
+		} else {
+			c.Next()
+			return
+		}
+
+		if err != nil {
+			if reason == "" {
+				loggerPkg.WithContext(c.Request.Context(), logger).Error("Failed to authenticate request", zap.Error(err))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"error":   "Internal Server Error",
+				})
+				return
+			}
+			rejectAuth(c, logger, http.StatusUnauthorized, reason, "invalid credentials", zap.Error(err))
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// Authorize enforces the route policy. Routes missing from the policy are denied,
+// requests that didn't match any route are left for gin to answer with 404.
+func Authorize(policy RoutePolicy, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("Authorize")
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		rule, ok := policy[c.Request.Method+" "+route]
+		if ok && rule.Public {
+			c.Next()
+			return
+		}
+
+		principal, authenticated := auth.FromContext(c.Request.Context())
+		if !authenticated {
+			rejectAuth(c, logger, http.StatusUnauthorized, "missing", "authentication is required")
+			return
+		}
+
+		if !ok || !principal.HasAnyRole(rule.Roles...) {
+			rejectAuth(c, logger, http.StatusForbidden, "insufficient_role", "access denied", zap.String("subject", principal.Subject))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func authenticateBearer(ctx context.Context, tokens TokenVerifier, header string) (*auth.Principal, string, error) {
+	scheme, token, _ := strings.Cut(header, " ")
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, "malformed", errors.New("unsupported authorization scheme")
+	}
+	if tokens == nil {
+		return nil, "jwt_disabled", errors.New("bearer tokens are not accepted")
+	}
+
+	principal, err := tokens.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, "invalid_token", err
+		}
+		return nil, "", err
+	}
+	return principal, "", nil
+}
+
+func authenticateAPIKey(ctx context.Context, apiKeys APIKeyAuthenticator, rawKey string) (*auth.Principal, string, error) {
+	key, err := apiKeys.Authenticate(ctx, rawKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrAPIKeyInvalid):
+			return nil, "invalid_api_key", err
+		case errors.Is(err, apperrors.ErrAPIKeyExpired):
+			return nil, "expired_api_key", err
+		}
+		return nil, "", err
+	}
+
+	roles := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		roles = append(roles, string(scope))
+	}
+
+	return &auth.Principal{
+		Subject:  "apikey:" + key.ID,
+		Method:   auth.MethodAPIKey,
+		Roles:    roles,
+		TenantID: key.TenantID,
+	}, "", nil
+}
+
+func rejectAuth(c *gin.Context, logger *zap.Logger, status int, reason, message string, fields ...zap.Field) {
+	metrics.AuthFailures.WithLabelValues(reason).Inc()
+
+	loggerPkg.WithContext(c.Request.Context(), logger).Warn("Authentication failed", append([]zap.Field{
+		zap.String("reason", reason),
+		zap.String("method", c.Request.Method),
+		zap.String("path", c.Request.URL.Path),
+		zap.String("ip", c.ClientIP()),
+	}, fields...)...)
+
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"error":   message,
+	})
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"products/internal/apperrors"
+	"products/internal/auth"
+	"products/internal/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeAuthenticator map[string]*models.APIKey
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, rawKey string) (*models.APIKey, error) {
+	if rawKey == "expired" {
+		return nil, apperrors.ErrAPIKeyExpired
+	}
+	key, ok := f[rawKey]
+	if !ok {
+		return nil, apperrors.ErrAPIKeyInvalid
+	}
+	return key, nil
+}
+
+type fakeVerifier map[string]*auth.Principal
+
+func (f fakeVerifier) Verify(_ context.Context, rawToken string) (*auth.Principal, error) {
+	p, ok := f[rawToken]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return p, nil
+}
+
+func TestAuthenticateAndAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	apiKeys := fakeAuthenticator{
+		"reader": {ID: "1", Scopes: []models.APIKeyScope{models.ScopeRead}},
+		"writer": {ID: "2", Scopes: []models.APIKeyScope{models.ScopeWrite}},
+		"admin":  {ID: "3", Scopes: []models.APIKeyScope{models.ScopeAdmin}},
+	}
+	tokens := fakeVerifier{
+		"editor": {Subject: "user-1", Method: auth.MethodJWT, Roles: []string{auth.RoleWrite}},
+		"viewer": {Subject: "user-2", Method: auth.MethodJWT, Roles: []string{auth.RoleRead}},
+	}
+	policy := RoutePolicy{
+		"GET /products":  {Public: true},
+		"POST /products": {Roles: []string{auth.RoleWrite}},
+	}
+
+	type testCase struct {
+		name            string
+		method          string
+		headers         map[string]string
+		expectedStatus  int
+		expectedSubject string
+	}
+
+	cases := []testCase{
+		{name: "Public Route Anonymous", method: "GET", expectedStatus: http.StatusOK},
+		{name: "Public Route Invalid Credentials", method: "GET", headers: map[string]string{APIKeyHeader: "unknown"}, expectedStatus: http.StatusUnauthorized},
+		{name: "Missing Credentials", method: "POST", expectedStatus: http.StatusUnauthorized},
+		{name: "Invalid API Key", method: "POST", headers: map[string]string{APIKeyHeader: "unknown"}, expectedStatus: http.StatusUnauthorized},
+		{name: "Expired API Key", method: "POST", headers: map[string]string{APIKeyHeader: "expired"}, expectedStatus: http.StatusUnauthorized},
+		{name: "API Key Insufficient Scope", method: "POST", headers: map[string]string{APIKeyHeader: "reader"}, expectedStatus: http.StatusForbidden},
+		{name: "API Key Write Scope", method: "POST", headers: map[string]string{APIKeyHeader: "writer"}, expectedStatus: http.StatusOK, expectedSubject: "apikey:2"},
+		{name: "API Key Admin Scope", method: "POST", headers: map[string]string{APIKeyHeader: "admin"}, expectedStatus: http.StatusOK, expectedSubject: "apikey:3"},
+		{name: "JWT Write Role", method: "POST", headers: map[string]string{"Authorization": "Bearer editor"}, expectedStatus: http.StatusOK, expectedSubject: "user-1"},
+		{name: "JWT Insufficient Role", method: "POST", headers: map[string]string{"Authorization": "Bearer viewer"}, expectedStatus: http.StatusForbidden},
+		{name: "JWT Invalid Token", method: "POST", headers: map[string]string{"Authorization": "Bearer forged"}, expectedStatus: http.StatusUnauthorized},
+		{name: "Unsupported Scheme", method: "POST", headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, expectedStatus: http.StatusUnauthorized},
+		{name: "Route Missing From Policy", method: "DELETE", headers: map[string]string{APIKeyHeader: "writer"}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Authenticate(tokens, apiKeys, zap.NewNop()), Authorize(policy, zap.NewNop()))
+			handler := func(c *gin.Context) {
+				assert.Equal(t, tCase.expectedSubject, auth.SubjectFromContext(c.Request.Context()))
+				c.Status(http.StatusOK)
+			}
+			router.GET("/products", handler)
+			router.POST("/products", handler)
+			router.DELETE("/products", handler)
+
+			req := httptest.NewRequest(tCase.method, "/products", nil)
+			for k, v := range tCase.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tCase.expectedStatus, w.Code)
+		})
+	}
+}
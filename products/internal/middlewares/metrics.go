@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"products/internal/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that didn't match any route, so random
+// paths don't create new series.
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics records request rate, errors and duration per route template.
+// Register it before middlewares that can reject requests so those are counted too.
+func HTTPMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		statusClass := strconv.Itoa(c.Writer.Status()/100) + "xx"
+
+		metrics.HTTPRequests.WithLabelValues(route, method, statusClass).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, method, statusClass).Observe(time.Since(start).Seconds())
+
+		if c.Request.ContentLength >= 0 {
+			metrics.HTTPRequestSize.WithLabelValues(route, method).Observe(float64(c.Request.ContentLength))
+		}
+		metrics.HTTPResponseSize.WithLabelValues(route, method).Observe(float64(max(c.Writer.Size(), 0)))
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"products/internal/metrics"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(HTTPMetrics())
+	router.DELETE("/products/:id", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false})
+	})
+
+	deletes := metrics.HTTPRequests.WithLabelValues("/products/:id", "DELETE", "4xx")
+	unmatched := metrics.HTTPRequests.WithLabelValues(unmatchedRoute, "POST", "4xx")
+	deletesBefore, unmatchedBefore := testutil.ToFloat64(deletes), testutil.ToFloat64(unmatched)
+
+	for _, id := range []string{"8f293f9f-9bd0-4294-bd17-4fb80aa2650a", "e9a9fa48-8674-49b7-a571-6514578a2864"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/products/"+id, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/unknown", strings.NewReader("{}")))
+
+	assert.Equal(t, deletesBefore+2, testutil.ToFloat64(deletes),
+		"Requests should be labeled by route template, not by raw path")
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.HTTPRequestsInFlight))
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"products/internal/auth"
+	loggerPkg "products/internal/logger"
+	"products/internal/metrics"
+	"products/internal/ratelimit"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RateLimit throttles requests per client with a token bucket. Authenticated clients
+// are keyed by their subject (API key or JWT subject), anonymous ones by IP.
+// class separates the buckets, so e.g. reads and writes get independent limits.
+// Run before Authenticate it limits every client by IP. A limit that isn't
+// enabled lets every request through.
+func RateLimit(store ratelimit.Store, class string, limit ratelimit.Limit, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("RateLimit")
+
+	if !limit.Enabled() {
+		logger.Info("Rate limit disabled", zap.String("class", class))
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		if subject := auth.SubjectFromContext(c.Request.Context()); subject != "" {
+			client = "sub:" + subject
+		}
+
+		res, err := store.Take(c.Request.Context(), class+":"+client, limit)
+		if err != nil {
+			// Fail open, an unavailable limiter backend shouldn't take the API down.
+			loggerPkg.WithContext(c.Request.Context(), logger).Error("Failed to check rate limit", zap.Error(err), zap.String("class", class))
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+		if !res.Allowed {
+			metrics.RateLimitedRequests.WithLabelValues(class).Inc()
+			loggerPkg.WithContext(c.Request.Context(), logger).Warn("Rate limit exceeded",
+				zap.String("class", class),
+				zap.String("client", client),
+				zap.String("path", c.Request.URL.Path),
+			)
+
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "rate limit exceeded",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"products/internal/auth"
+	"products/internal/ratelimit"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := ratelimit.NewMemoryStore()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if subject := c.GetHeader("X-Test-Subject"); subject != "" {
+			c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), &auth.Principal{Subject: subject}))
+		}
+	})
+	router.POST("/products", RateLimit(store, "write", ratelimit.Limit{Rate: 0.5, Burst: 1}, zap.NewNop()), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	send := func(subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/products", nil)
+		if subject != "" {
+			req.Header.Set("X-Test-Subject", subject)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("apikey:1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	w = send("apikey:1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusCreated, send("apikey:2").Code, "Other clients should have their own bucket")
+	assert.Equal(t, http.StatusCreated, send("").Code, "Anonymous clients should be limited by IP")
+	assert.Equal(t, http.StatusTooManyRequests, send("").Code)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type testCase struct {
+		name  string
+		limit ratelimit.Limit
+	}
+
+	cases := []testCase{
+		{name: "Zero Rate", limit: ratelimit.Limit{Rate: 0, Burst: 10}},
+		{name: "Zero Burst", limit: ratelimit.Limit{Rate: 5, Burst: 0}},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/products", RateLimit(ratelimit.NewMemoryStore(), "read", tCase.limit, zap.NewNop()), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			for range 3 {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest("GET", "/products", nil))
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"products/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID reuses the client's X-Request-ID or generates one, stores it in the
+// request context and echoes it in the response. Register it before other middlewares.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set("request_id", id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"products/internal/requestid"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type testCase struct {
+		name     string
+		incoming string
+		reused   bool
+	}
+
+	cases := []testCase{
+		{name: "Generated When Missing", incoming: "", reused: false},
+		{name: "Reused When Valid", incoming: "req-123", reused: true},
+		{name: "Replaced When Too Long", incoming: strings.Repeat("a", 200), reused: false},
+		{name: "Replaced When Not Printable", incoming: "req 123\n", reused: false},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			var seen string
+			router := gin.New()
+			router.Use(RequestID())
+			router.GET("/products", func(c *gin.Context) {
+				seen, _ = requestid.FromContext(c.Request.Context())
+				assert.Equal(t, seen, c.GetString("request_id"))
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/products", nil)
+			if tCase.incoming != "" {
+				req.Header.Set(requestid.Header, tCase.incoming)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.NotEmpty(t, seen)
+			assert.Equal(t, seen, w.Header().Get(requestid.Header), "Request ID should be echoed in the response")
+			if tCase.reused {
+				assert.Equal(t, tCase.incoming, seen)
+			} else {
+				assert.NotEqual(t, tCase.incoming, seen)
+			}
+		})
+	}
+}
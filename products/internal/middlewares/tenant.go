@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"products/internal/auth"
+	loggerPkg "products/internal/logger"
+	"products/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ResolveTenant stores the request tenant in the request context. A tenant bound
+// principal always acts on its own tenant, an unbound principal picks one with
+// the X-Tenant-ID header and anonymous callers only get tenant.Default, so public
+// routes can't be used to read other tenants. Must run after Authenticate.
+func ResolveTenant(logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("ResolveTenant")
+
+	return func(c *gin.Context) {
+		tenantID := c.GetHeader(tenant.Header)
+
+		principal, authenticated := auth.FromContext(c.Request.Context())
+		if !authenticated && tenantID != "" && tenantID != tenant.Default {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "authentication required for tenant",
+			})
+			return
+		}
+
+		if authenticated && principal.TenantID != "" {
+			if tenantID != "" && tenantID != principal.TenantID {
+				loggerPkg.WithContext(c.Request.Context(), logger).Warn("Tenant mismatch",
+					zap.String("subject", principal.Subject),
+					zap.String("requested_tenant", tenantID),
+					zap.String("tenant", principal.TenantID),
+				)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"success": false,
+					"error":   "access to tenant denied",
+				})
+				return
+			}
+			tenantID = principal.TenantID
+		}
+
+		if tenantID == "" {
+			tenantID = tenant.Default
+		}
+
+		if !tenant.Valid(tenantID) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid tenant id",
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), tenantID))
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"products/internal/auth"
+	"products/internal/tenant"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestResolveTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type testCase struct {
+		name           string
+		principal      *auth.Principal
+		header         string
+		expectedStatus int
+		expectedTenant string
+	}
+
+	cases := []testCase{
+		{name: "Default Tenant", expectedStatus: http.StatusOK, expectedTenant: tenant.Default},
+		{name: "Anonymous Default Header Tenant", header: tenant.Default, expectedStatus: http.StatusOK, expectedTenant: tenant.Default},
+		{name: "Anonymous Header Tenant", header: "brand-a", expectedStatus: http.StatusUnauthorized},
+		{name: "Invalid Header Tenant", principal: &auth.Principal{Subject: "u"}, header: "brand a;", expectedStatus: http.StatusBadRequest},
+		{name: "Principal Tenant", principal: &auth.Principal{Subject: "u", TenantID: "brand-b"}, expectedStatus: http.StatusOK, expectedTenant: "brand-b"},
+		{name: "Principal Tenant Matches Header", principal: &auth.Principal{Subject: "u", TenantID: "brand-b"}, header: "brand-b", expectedStatus: http.StatusOK, expectedTenant: "brand-b"},
+		{name: "Principal Tenant Mismatch", principal: &auth.Principal{Subject: "u", TenantID: "brand-b"}, header: "brand-a", expectedStatus: http.StatusForbidden},
+		{name: "Unbound Principal Uses Header", principal: &auth.Principal{Subject: "u"}, header: "brand-a", expectedStatus: http.StatusOK, expectedTenant: "brand-a"},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tCase.principal != nil {
+					c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), tCase.principal))
+				}
+			}, ResolveTenant(zap.NewNop()))
+			router.GET("/products", func(c *gin.Context) {
+				tenantID, _ := tenant.FromContext(c.Request.Context())
+				assert.Equal(t, tCase.expectedTenant, tenantID)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/products", nil)
+			if tCase.header != "" {
+				req.Header.Set(tenant.Header, tCase.header)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tCase.expectedStatus, w.Code)
+		})
+	}
+}
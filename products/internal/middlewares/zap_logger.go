@@ -1,18 +1,31 @@
 package middleware
 
 import (
+	"products/internal/auth"
+	"products/internal/tenant"
 	"time"
 
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func ZapLoggerMiddleware(logger *zap.Logger) gin.HandlerFunc {
 	return ginzap.GinzapWithConfig(logger, &ginzap.Config{
 		TimeFormat: time.RFC3339,
 		UTC:        true,
-		SkipPaths:  []string{"/health", "/metrics"},
+		SkipPaths:  []string{"/health/live", "/health/ready", "/metrics"},
+		Context: func(c *gin.Context) []zapcore.Field {
+			fields := []zapcore.Field{zap.String("request_id", c.GetString("request_id"))}
+			if subject := auth.SubjectFromContext(c.Request.Context()); subject != "" {
+				fields = append(fields, zap.String("subject", subject))
+			}
+			if tenantID, ok := tenant.FromContext(c.Request.Context()); ok {
+				fields = append(fields, zap.String("tenant_id", tenantID))
+			}
+			return fields
+		},
 	})
 }
 
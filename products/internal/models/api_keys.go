@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type APIKeyScope string
+
+const (
+	ScopeRead  APIKeyScope = "read"
+	ScopeWrite APIKeyScope = "write"
+	// ScopeAdmin grants every other scope.
+	ScopeAdmin APIKeyScope = "admin"
+)
+
+func (s APIKeyScope) Valid() bool {
+	switch s {
+	case ScopeRead, ScopeWrite, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+type APIKey struct {
+	ID     string        `json:"id"`
+	Label  string        `json:"label"`
+	Scopes []APIKeyScope `json:"scopes"`
+	// TenantID binds the key to a single tenant, empty means the key may act on any tenant.
+	TenantID string `json:"tenant_id,omitempty"`
+	// KeyHash is the hex encoded SHA-256 of the raw key, the raw key itself is never stored.
+	KeyHash   string     `json:"-"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+type CreateAPIKeyDTO struct {
+	Label     string
+	Scopes    []APIKeyScope
+	TenantID  string
+	ExpiresAt *time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// DeliveryReport is the outcome of a message sent through the message broker.
+type DeliveryReport struct {
+	Topic     string
+	Key       []byte
+	Partition int32
+	Offset    int64
+	RequestID string
+	// Latency is the time from producing the message to receiving its report.
+	Latency time.Duration
+	// Err is set when the message was not delivered.
+	Err error
+}
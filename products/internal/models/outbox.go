@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// OutboxMessage is an event waiting in the outbox to be published.
+type OutboxMessage struct {
+	ID       int64
+	TenantID string
+	// AggregateID identifies the entity the event is about. Messages of one
+	// aggregate are published in order and it is used as the message key.
+	AggregateID string
+	EventType   string
+	// Topic is the destination topic, empty means the broker's default topic.
+	Topic   string
+	Payload []byte
+	// Headers carry the request id and trace context of the originating request.
+	Headers   map[string]string
+	Attempts  int
+	CreatedAt time.Time
+}
+
+type OutboxStats struct {
+	Pending int
+	// Oldest is the creation time of the oldest pending message, zero when there is none.
+	Oldest time.Time
+}
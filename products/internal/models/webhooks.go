@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryFailed is final, the delivery ran out of attempts.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+type WebhookSubscription struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id"`
+	URL      string `json:"url"`
+	// EventTypes the subscription receives, empty means every event type.
+	EventTypes []string `json:"event_types"`
+	// Secret signs the deliveries, it is only returned when the subscription is created.
+	Secret string `json:"-"`
+	Active bool   `json:"active"`
+	// ConsecutiveFailures counts failed delivery attempts since the last success.
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	DisabledReason      string    `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to one subscription, with the outcome of its last attempt.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID string                `json:"subscription_id"`
+	EventID        string                `json:"event_id"`
+	EventType      string                `json:"event_type"`
+	Payload        []byte                `json:"-"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+
+	// URL and Secret are the subscription's, set on deliveries claimed for sending.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookAttempt is the outcome of a failed delivery attempt.
+type WebhookAttempt struct {
+	// ResponseStatus is 0 when no response was received.
+	ResponseStatus int
+	Error          string
+	// NextAttemptAt is nil when the delivery ran out of attempts.
+	NextAttemptAt *time.Time
+}
+
+type CreateWebhookDTO struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types"`
+	// Secret is generated when empty.
+	Secret string `json:"secret"`
+}
+
+// UpdateWebhookDTO changes the fields that are set. Activating a subscription
+// resets its failure count.
+type UpdateWebhookDTO struct {
+	URL        *string   `json:"url"`
+	EventTypes *[]string `json:"event_types"`
+	Active     *bool     `json:"active"`
+}
+
+type WebhookIDDTO struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
@@ -0,0 +1,27 @@
+package outbox
+
+import (
+	"context"
+	"products/internal/requestid"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ContextHeaders captures the request id and trace context of ctx, so the relay
+// can publish the message as part of the request that caused it.
+func ContextHeaders(ctx context.Context) map[string]string {
+	headers := map[string]string{}
+	if id, ok := requestid.FromContext(ctx); ok {
+		headers[requestid.MessageHeader] = id
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+	return headers
+}
+
+func contextFromHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if id := headers[requestid.MessageHeader]; id != "" {
+		ctx = requestid.NewContext(ctx, id)
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
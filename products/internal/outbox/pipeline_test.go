@@ -0,0 +1,190 @@
+package outbox_test
+
+import (
+	"context"
+	"contracts/membroker"
+	"contracts/productevent"
+	"fmt"
+	"products/internal/messaging"
+	"products/internal/models"
+	"products/internal/outbox"
+	"products/internal/serde"
+	"products/internal/services"
+	"products/internal/tenant"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryOutbox is an outbox table in memory. It wakes the relay on Enqueue
+// like the NOTIFY trigger does.
+type memoryOutbox struct {
+	mu       sync.Mutex
+	messages []models.OutboxMessage
+	sent     map[int64]bool
+	wake     chan struct{}
+}
+
+func (o *memoryOutbox) Enqueue(ctx context.Context, msg *models.OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	msg.ID = int64(len(o.messages) + 1)
+	o.messages = append(o.messages, *msg)
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// Like the outbox table, only the oldest pending message of an aggregate is due.
+	var pending []models.OutboxMessage
+	held := map[string]bool{}
+	for _, msg := range o.messages {
+		if o.sent[msg.ID] || held[msg.AggregateID] {
+			continue
+		}
+		held[msg.AggregateID] = true
+		if len(pending) < limit {
+			pending = append(pending, msg)
+		}
+	}
+	return pending, nil
+}
+
+func (o *memoryOutbox) MarkSent(ctx context.Context, id int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sent[id] = true
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) error {
+	return nil
+}
+
+func (o *memoryOutbox) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (o *memoryOutbox) Stats(ctx context.Context) (models.OutboxStats, error) {
+	return models.OutboxStats{}, nil
+}
+
+type memoryProducts struct {
+	mu       sync.Mutex
+	products map[string]*models.Product
+}
+
+func (r *memoryProducts) Create(ctx context.Context, createDTO *models.CreateProductDTO) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	product := &models.Product{
+		ID:          fmt.Sprintf("uuid-%d", len(r.products)+1),
+		Name:        createDTO.Name,
+		Description: createDTO.Description,
+		Price:       createDTO.Price,
+		CreatedAt:   time.Now().UTC(),
+	}
+	r.products[product.ID] = product
+	return product, nil
+}
+
+func (r *memoryProducts) Delete(ctx context.Context, id string) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	product, ok := r.products[id]
+	if !ok {
+		return nil, fmt.Errorf("product %s not found", id)
+	}
+	delete(r.products, id)
+	return product, nil
+}
+
+func (r *memoryProducts) List(ctx context.Context, listDTO *models.ListProductsDTO) ([]models.Product, error) {
+	return nil, nil
+}
+
+func (r *memoryProducts) Count(ctx context.Context) (int, error) {
+	return len(r.products), nil
+}
+
+type inlineTx struct{}
+
+func (inlineTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// TestPipeline runs product changes through the outbox, the relay and the
+// in-memory broker to a consumer group, without Postgres or Kafka.
+func TestPipeline(t *testing.T) {
+	ctx, cancel := context.WithCancel(tenant.NewContext(context.Background(), "brand-a"))
+	defer cancel()
+
+	store := &memoryOutbox{sent: map[int64]bool{}, wake: make(chan struct{}, 1)}
+	service := services.NewProductsService(&memoryProducts{products: map[string]*models.Product{}}, store, nil, nil, inlineTx{}, serde.JSONSerializer{}, zap.NewNop())
+
+	broker := membroker.New(3)
+	defer broker.Close()
+	relay := outbox.NewRelay(store, messaging.NewMemoryBroker(broker, messaging.Config{Topic: "product-events", BaseClientID: "product-service"}), outbox.Config{
+		PollInterval:    time.Minute,
+		BatchSize:       10,
+		PublishTimeout:  time.Second,
+		MinBackoff:      time.Second,
+		MaxBackoff:      time.Minute,
+		CleanupInterval: time.Hour,
+	}, zap.NewNop())
+	go relay.Run(ctx, store.wake)
+
+	var (
+		mu       sync.Mutex
+		received []*productevent.Event
+		headers  []map[string]string
+	)
+	go func() {
+		_ = broker.Consume(ctx, "product-events", "notifications", 2, func(ctx context.Context, msg *membroker.Message) error {
+			event, err := productevent.DecodeJSON(msg.Value)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, event)
+			headers = append(headers, msg.Headers)
+			return nil
+		})
+	}()
+
+	phone, err := service.Create(ctx, &models.CreateProductDTO{Name: "Phone", Price: 50000})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, &models.CreateProductDTO{Name: "Laptop", Price: 150000})
+	require.NoError(t, err)
+	_, err = service.Delete(ctx, phone.ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var phoneEvents []productevent.Type
+	for i, event := range received {
+		assert.Equal(t, "brand-a", event.TenantID)
+		assert.Equal(t, event.Type.CloudEventType(), headers[i]["event-type"])
+		assert.Equal(t, "product-service", headers[i]["producer"])
+		if event.Product.ID == phone.ID {
+			phoneEvents = append(phoneEvents, event.Type)
+		}
+	}
+	assert.Equal(t, []productevent.Type{productevent.Created, productevent.Deleted}, phoneEvents, "Events of a product should arrive in order")
+}
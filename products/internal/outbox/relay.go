@@ -0,0 +1,266 @@
+package outbox
+
+import (
+	"context"
+	loggerPkg "products/internal/logger"
+	"products/internal/metrics"
+	"products/internal/models"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) error
+	DeleteSent(ctx context.Context, before time.Time) (int64, error)
+	Stats(ctx context.Context) (models.OutboxStats, error)
+}
+
+type MessageBroker interface {
+	Send(ctx context.Context, topic string, message, key []byte, headers map[string]string) error
+}
+
+// leaseMargin is added to the publish timeout for the lease of a claimed batch.
+const leaseMargin = 5 * time.Second
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// PublishTimeout bounds a single Send.
+	PublishTimeout time.Duration
+	// AsyncDelivery means Send returns before the broker acknowledged the
+	// message. Messages are then marked from the delivery reports passed to
+	// HandleDeliveryReport instead.
+	AsyncDelivery bool
+	MinBackoff    time.Duration
+	MaxBackoff    time.Duration
+	// Retention is how long sent messages are kept before cleanup.
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+// Relay publishes outbox messages through the message broker and marks them
+// sent. Messages are claimed with a lease instead of being locked while they
+// are published. Delivery is at least once: a message may be published again
+// when marking it sent fails or the lease runs out.
+type Relay struct {
+	repo   Repository
+	broker MessageBroker
+	cfg    Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	// inflight holds the messages awaiting their delivery report with
+	// AsyncDelivery, by aggregate id, which has one pending message at a time.
+	mu       sync.Mutex
+	inflight map[string]models.OutboxMessage
+}
+
+func NewRelay(repo Repository, broker MessageBroker, cfg Config, logger *zap.Logger) *Relay {
+	return &Relay{
+		repo:     repo,
+		broker:   broker,
+		cfg:      cfg,
+		logger:   logger.Named("OutboxRelay"),
+		now:      time.Now,
+		inflight: map[string]models.OutboxMessage{},
+	}
+}
+
+// Run publishes pending messages whenever wake fires and at least every poll
+// interval, and removes old sent messages every cleanup interval until ctx is
+// done. wake may be nil to rely on polling alone.
+func (r *Relay) Run(ctx context.Context, wake <-chan struct{}) {
+	poll := time.NewTicker(r.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(r.cfg.CleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		r.drain(ctx)
+		r.updateStats(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-poll.C:
+		case <-cleanup.C:
+			r.cleanup(ctx)
+		}
+	}
+}
+
+// drain publishes batches until there is nothing due left. A batch holds one
+// message per aggregate, so a short batch doesn't mean nothing is due anymore.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		fetched, err := r.publishBatch(ctx)
+		if err != nil {
+			r.logger.Error("Failed to publish outbox batch:", zap.Error(err))
+			return
+		}
+		if fetched == 0 {
+			return
+		}
+	}
+}
+
+// publishBatch publishes one batch of due messages and returns how many were fetched.
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	// The messages are published concurrently, so the batch is done within the
+	// publish timeout and a relay that dies only holds them back that long.
+	messages, err := r.repo.Claim(ctx, r.cfg.BatchSize, r.cfg.PublishTimeout+leaseMargin)
+	if err != nil {
+		return 0, err
+	}
+
+	// A batch holds at most one message per aggregate, so aggregates stay in order.
+	errs := make([]error, len(messages))
+	published := make([]bool, len(messages))
+	var wg sync.WaitGroup
+	for i, msg := range messages {
+		// A message whose report is overdue stays claimed until the report arrives.
+		if r.cfg.AsyncDelivery && !r.track(msg) {
+			continue
+		}
+		published[i] = true
+		wg.Go(func() {
+			errs[i] = r.publish(ctx, msg)
+		})
+	}
+	wg.Wait()
+
+	for i, msg := range messages {
+		if !published[i] {
+			continue
+		}
+		if errs[i] != nil {
+			if r.cfg.AsyncDelivery {
+				r.untrack(msg.AggregateID)
+			}
+			if err := r.markFailed(ctx, msg, errs[i]); err != nil {
+				return len(messages), err
+			}
+			continue
+		}
+		if r.cfg.AsyncDelivery {
+			continue
+		}
+
+		if err := r.repo.MarkSent(ctx, msg.ID); err != nil {
+			return len(messages), err
+		}
+		metrics.OutboxPublished.Inc()
+	}
+	return len(messages), nil
+}
+
+// HandleDeliveryReport marks the message a delivery report is for sent or
+// failed, with AsyncDelivery. Reports of other messages are ignored.
+func (r *Relay) HandleDeliveryReport(report models.DeliveryReport) {
+	msg, ok := r.untrack(string(report.Key))
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(contextFromHeaders(context.Background(), msg.Headers), r.cfg.PublishTimeout)
+	defer cancel()
+
+	if report.Err != nil {
+		if err := r.markFailed(ctx, msg, report.Err); err != nil {
+			r.logger.Error("Failed to mark outbox message failed:", zap.Error(err), zap.Int64("outbox_id", msg.ID))
+		}
+		return
+	}
+	if err := r.repo.MarkSent(ctx, msg.ID); err != nil {
+		r.logger.Error("Failed to mark outbox message sent:", zap.Error(err), zap.Int64("outbox_id", msg.ID))
+		return
+	}
+	metrics.OutboxPublished.Inc()
+}
+
+// track records msg as awaiting its delivery report, false when a message of
+// its aggregate already is.
+func (r *Relay) track(msg models.OutboxMessage) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.inflight[msg.AggregateID]; ok {
+		return false
+	}
+	r.inflight[msg.AggregateID] = msg
+	return true
+}
+
+func (r *Relay) untrack(aggregateID string) (models.OutboxMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	msg, ok := r.inflight[aggregateID]
+	delete(r.inflight, aggregateID)
+	return msg, ok
+}
+
+// markFailed records a failed publish of msg and postpones its next attempt.
+func (r *Relay) markFailed(ctx context.Context, msg models.OutboxMessage, err error) error {
+	metrics.OutboxPublishFailures.Inc()
+	backoff := r.backoff(msg.Attempts + 1)
+	loggerPkg.WithContext(contextFromHeaders(ctx, msg.Headers), r.logger).Warn("Failed to publish outbox message:",
+		zap.Error(err),
+		zap.Int64("outbox_id", msg.ID),
+		zap.String("aggregate_id", msg.AggregateID),
+		zap.String("event_type", msg.EventType),
+		zap.Int("attempts", msg.Attempts+1),
+		zap.Duration("retry_in", backoff),
+	)
+
+	return r.repo.MarkFailed(ctx, msg.ID, err.Error(), r.now().Add(backoff))
+}
+
+func (r *Relay) publish(ctx context.Context, msg models.OutboxMessage) error {
+	ctx, cancel := context.WithTimeout(contextFromHeaders(ctx, msg.Headers), r.cfg.PublishTimeout)
+	defer cancel()
+
+	return r.broker.Send(ctx, msg.Topic, msg.Payload, []byte(msg.AggregateID), msg.Headers)
+}
+
+// backoff doubles the delay with every attempt, starting at MinBackoff and capped at MaxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.cfg.MinBackoff
+	for i := 1; i < attempts && delay < r.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.cfg.MaxBackoff)
+}
+
+func (r *Relay) updateStats(ctx context.Context) {
+	stats, err := r.repo.Stats(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get outbox stats:", zap.Error(err))
+		return
+	}
+
+	metrics.OutboxPending.Set(float64(stats.Pending))
+	if stats.Oldest.IsZero() {
+		metrics.OutboxOldestAge.Set(0)
+	} else {
+		metrics.OutboxOldestAge.Set(r.now().Sub(stats.Oldest).Seconds())
+	}
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.repo.DeleteSent(ctx, r.now().Add(-r.cfg.Retention))
+	if err != nil {
+		r.logger.Error("Failed to clean up outbox:", zap.Error(err))
+		return
+	}
+
+	metrics.OutboxDeleted.Add(float64(deleted))
+	if deleted > 0 {
+		r.logger.Info("Cleaned up outbox", zap.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,245 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"products/internal/models"
+	"products/internal/requestid"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OutboxMessage), args.Error(1)
+}
+
+func (m *MockRepository) MarkSent(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkFailed(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, reason, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) Stats(ctx context.Context) (models.OutboxStats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.OutboxStats), args.Error(1)
+}
+
+type MockMessageBroker struct {
+	mock.Mock
+}
+
+func (m *MockMessageBroker) Send(ctx context.Context, topic string, message, key []byte, headers map[string]string) error {
+	args := m.Called(ctx, topic, message, key, headers)
+	return args.Error(0)
+}
+
+func newTestRelay(repo Repository, broker MessageBroker) *Relay {
+	relay := NewRelay(repo, broker, Config{
+		BatchSize:      10,
+		PublishTimeout: time.Second,
+		MinBackoff:     time.Second,
+		MaxBackoff:     time.Minute,
+		Retention:      24 * time.Hour,
+	}, zap.NewNop())
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return now }
+	return relay
+}
+
+func TestRelayPublishBatch(t *testing.T) {
+	ctx := context.Background()
+
+	messages := []models.OutboxMessage{
+		{ID: 1, AggregateID: "uuid-1", EventType: "product_created", Payload: []byte(`{"a":1}`), Headers: map[string]string{requestid.MessageHeader: "req-1", "event-type": "products.product_created"}},
+		{ID: 2, AggregateID: "uuid-2", EventType: "product_deleted", Payload: []byte(`{"b":2}`), Attempts: 2},
+	}
+
+	repo := new(MockRepository)
+	broker := new(MockMessageBroker)
+	relay := newTestRelay(repo, broker)
+
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return(messages, nil).Once()
+	broker.On("Send", mock.MatchedBy(func(ctx context.Context) bool {
+		id, _ := requestid.FromContext(ctx)
+		return id == "req-1"
+	}), "", []byte(`{"a":1}`), []byte("uuid-1"), messages[0].Headers).Return(nil).Once()
+	broker.On("Send", mock.Anything, "", []byte(`{"b":2}`), []byte("uuid-2"), map[string]string(nil)).Return(errors.New("broker down")).Once()
+	repo.On("MarkSent", mock.Anything, int64(1)).Return(nil).Once()
+	repo.On("MarkFailed", mock.Anything, int64(2), "broker down", relay.now().Add(4*time.Second)).Return(nil).Once()
+
+	fetched, err := relay.publishBatch(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched)
+	repo.AssertExpectations(t)
+	broker.AssertExpectations(t)
+}
+
+func TestRelayAsyncDelivery(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	broker := new(MockMessageBroker)
+	relay := newTestRelay(repo, broker)
+	relay.cfg.AsyncDelivery = true
+
+	messages := []models.OutboxMessage{
+		{ID: 1, AggregateID: "uuid-1", Payload: []byte("created")},
+		{ID: 2, AggregateID: "uuid-2", Payload: []byte("created"), Attempts: 1},
+	}
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return(messages, nil).Once()
+	broker.On("Send", mock.Anything, "", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+
+	_, err := relay.publishBatch(ctx)
+	require.NoError(t, err)
+	repo.AssertNotCalled(t, "MarkSent", mock.Anything, mock.Anything)
+
+	// Claimed again once the lease ran out, it isn't sent while its report is due.
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return(messages[:1], nil).Once()
+	_, err = relay.publishBatch(ctx)
+	require.NoError(t, err)
+	broker.AssertNumberOfCalls(t, "Send", 2)
+
+	repo.On("MarkSent", mock.Anything, int64(1)).Return(nil).Once()
+	repo.On("MarkFailed", mock.Anything, int64(2), "message timed out", relay.now().Add(2*time.Second)).Return(nil).Once()
+	relay.HandleDeliveryReport(models.DeliveryReport{Key: []byte("uuid-1")})
+	relay.HandleDeliveryReport(models.DeliveryReport{Key: []byte("uuid-2"), Err: errors.New("message timed out")})
+	relay.HandleDeliveryReport(models.DeliveryReport{Key: []byte("uuid-1")})
+
+	repo.AssertExpectations(t)
+	broker.AssertExpectations(t)
+}
+
+func TestRelayDrain(t *testing.T) {
+	repo := new(MockRepository)
+	broker := new(MockMessageBroker)
+	relay := newTestRelay(repo, broker)
+
+	// The second event of uuid-1 is only claimed once the first one was sent.
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return([]models.OutboxMessage{
+		{ID: 1, AggregateID: "uuid-1", Payload: []byte("created")},
+		{ID: 2, AggregateID: "uuid-2", Payload: []byte("created")},
+	}, nil).Once()
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return([]models.OutboxMessage{
+		{ID: 3, AggregateID: "uuid-1", Payload: []byte("deleted")},
+	}, nil).Once()
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return([]models.OutboxMessage{}, nil).Once()
+	broker.On("Send", mock.Anything, "", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(3)
+	repo.On("MarkSent", mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	relay.drain(context.Background())
+
+	repo.AssertExpectations(t)
+	broker.AssertExpectations(t)
+}
+
+func TestRelayPublishBatchRepositoryError(t *testing.T) {
+	repoErr := errors.New("repository error")
+	repo := new(MockRepository)
+	broker := new(MockMessageBroker)
+	relay := newTestRelay(repo, broker)
+
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return(nil, repoErr).Once()
+
+	_, err := relay.publishBatch(context.Background())
+
+	assert.Equal(t, repoErr, err)
+	broker.AssertNotCalled(t, "Send")
+}
+
+func TestRelayBackoff(t *testing.T) {
+	relay := newTestRelay(new(MockRepository), new(MockMessageBroker))
+
+	type testCase struct {
+		attempts int
+		expected time.Duration
+	}
+
+	cases := []testCase{
+		{attempts: 1, expected: time.Second},
+		{attempts: 2, expected: 2 * time.Second},
+		{attempts: 5, expected: 16 * time.Second},
+		{attempts: 7, expected: time.Minute},
+		{attempts: 100, expected: time.Minute},
+	}
+
+	for _, tCase := range cases {
+		assert.Equal(t, tCase.expected, relay.backoff(tCase.attempts), "attempts %d", tCase.attempts)
+	}
+}
+
+func TestRelayCleanup(t *testing.T) {
+	repo := new(MockRepository)
+	relay := newTestRelay(repo, new(MockMessageBroker))
+
+	repo.On("DeleteSent", mock.Anything, relay.now().Add(-24*time.Hour)).Return(int64(3), nil).Once()
+
+	relay.cleanup(context.Background())
+
+	repo.AssertExpectations(t)
+}
+
+func TestContextHeaders(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	headers := ContextHeaders(ctx)
+	assert.Equal(t, "req-1", headers[requestid.MessageHeader])
+
+	id, ok := requestid.FromContext(contextFromHeaders(context.Background(), headers))
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", id)
+}
+
+func TestRelayRunWakeUp(t *testing.T) {
+	repo := new(MockRepository)
+	relay := newTestRelay(repo, new(MockMessageBroker))
+	relay.cfg.PollInterval = time.Hour
+	relay.cfg.CleanupInterval = time.Hour
+
+	polled := make(chan struct{}, 2)
+	repo.On("Claim", mock.Anything, 10, 6*time.Second).Return([]models.OutboxMessage{}, nil).Run(func(mock.Arguments) {
+		polled <- struct{}{}
+	})
+	repo.On("Stats", mock.Anything).Return(models.OutboxStats{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wake := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx, wake)
+	}()
+
+	<-polled
+	wake <- struct{}{}
+
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("Relay should poll right after a wake up")
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+
+	t.Run("Burst", func(t *testing.T) {
+		for remaining := 1; remaining >= 0; remaining-- {
+			res, err := store.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, remaining, res.Remaining)
+			assert.Equal(t, 2, res.Limit)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		res, err := store.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, time.Second, res.RetryAfter)
+		assert.Equal(t, 2*time.Second, res.ResetAfter)
+	})
+
+	t.Run("Other Keys Are Independent", func(t *testing.T) {
+		res, err := store.Take(ctx, "other-client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		now = now.Add(time.Second)
+		res, err := store.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+	})
+}
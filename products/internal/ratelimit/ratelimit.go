@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is a token bucket: Rate tokens per second are added up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled reports whether the limit can be enforced. A limit without a positive
+// rate and a burst of at least one request means no limit.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst >= 1
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request would be allowed, zero when allowed.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// Store keeps the buckets. The in-memory store is per instance, a shared backend
+// (e.g. Redis) can implement the same interface to limit across replicas.
+// Take expects an enabled limit.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -0,0 +1,149 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"products/internal/apperrors"
+	"products/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type APIKeysRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeysRepository(db *sqlx.DB) *APIKeysRepository {
+	return &APIKeysRepository{
+		db: db,
+	}
+}
+
+type apiKeyRow struct {
+	ID        string         `db:"id"`
+	Label     string         `db:"label"`
+	KeyHash   string         `db:"key_hash"`
+	Scopes    pq.StringArray `db:"scopes"`
+	TenantID  *string        `db:"tenant_id"`
+	ExpiresAt *time.Time     `db:"expires_at"`
+	RevokedAt *time.Time     `db:"revoked_at"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (r *apiKeyRow) toModel() *models.APIKey {
+	scopes := make([]models.APIKeyScope, 0, len(r.Scopes))
+	for _, s := range r.Scopes {
+		scopes = append(scopes, models.APIKeyScope(s))
+	}
+
+	var tenantID string
+	if r.TenantID != nil {
+		tenantID = *r.TenantID
+	}
+
+	return &models.APIKey{
+		ID:        r.ID,
+		Label:     r.Label,
+		Scopes:    scopes,
+		TenantID:  tenantID,
+		KeyHash:   r.KeyHash,
+		ExpiresAt: r.ExpiresAt,
+		RevokedAt: r.RevokedAt,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func (r *APIKeysRepository) Create(ctx context.Context, key *models.APIKey) (_ *models.APIKey, err error) {
+	ctx, end := instrument(ctx, "APIKeysRepository.Create")
+	defer end(&err)
+
+	var query = `
+		INSERT INTO api_keys (label, key_hash, scopes, tenant_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, label, key_hash, scopes, tenant_id, expires_at, revoked_at, created_at
+	`
+	scopes := make(pq.StringArray, 0, len(key.Scopes))
+	for _, s := range key.Scopes {
+		scopes = append(scopes, string(s))
+	}
+
+	var tenantID *string
+	if key.TenantID != "" {
+		tenantID = &key.TenantID
+	}
+
+	var row apiKeyRow
+	err = r.db.GetContext(ctx, &row, query, key.Label, key.KeyHash, scopes, tenantID, key.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *APIKeysRepository) GetByHash(ctx context.Context, hash string) (_ *models.APIKey, err error) {
+	ctx, end := instrument(ctx, "APIKeysRepository.GetByHash")
+	defer end(&err)
+
+	var query = `
+		SELECT id, label, key_hash, scopes, tenant_id, expires_at, revoked_at, created_at FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+	var row apiKeyRow
+	err = r.db.GetContext(ctx, &row, query, hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrAPIKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *APIKeysRepository) List(ctx context.Context) (_ []models.APIKey, err error) {
+	ctx, end := instrument(ctx, "APIKeysRepository.List")
+	defer end(&err)
+
+	var query = `
+		SELECT id, label, key_hash, scopes, tenant_id, expires_at, revoked_at, created_at FROM api_keys
+		ORDER BY created_at
+	`
+	var rows []apiKeyRow
+	err = r.db.SelectContext(ctx, &rows, query)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]models.APIKey, 0, len(rows))
+	for i := range rows {
+		keys = append(keys, *rows[i].toModel())
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeysRepository) Revoke(ctx context.Context, id string) (_ *models.APIKey, err error) {
+	ctx, end := instrument(ctx, "APIKeysRepository.Revoke")
+	defer end(&err)
+
+	var query = `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING id, label, key_hash, scopes, tenant_id, expires_at, revoked_at, created_at
+	`
+	var row apiKeyRow
+	err = r.db.GetContext(ctx, &row, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrAPIKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
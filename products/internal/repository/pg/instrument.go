@@ -0,0 +1,39 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"products/internal/apperrors"
+	"products/internal/metrics"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("products/internal/repository/pg")
+
+// instrument starts a span for a repository method and records its latency and
+// failures. The SQL statements it runs show up as child spans. Call the returned
+// function with the method's error.
+func instrument(ctx context.Context, method string) (context.Context, func(err *error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+
+	return ctx, func(err *error) {
+		metrics.DBQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+		if *err != nil && !isExpected(*err) {
+			metrics.DBQueryErrors.WithLabelValues(method).Inc()
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+// isExpected reports errors that are valid outcomes rather than database failures.
+func isExpected(err error) bool {
+	return apperrors.IsNotFoundError(err) || errors.Is(err, apperrors.ErrAPIKeyNotFound) || errors.Is(err, apperrors.ErrWebhookNotFound)
+}
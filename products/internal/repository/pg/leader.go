@@ -0,0 +1,134 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"products/internal/metrics"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// OutboxRelayLock is the advisory lock key held by the replica running the outbox relay.
+const OutboxRelayLock int64 = 0x6f7574626f78 // "outbox"
+
+// LeaderElector makes sure a function runs on one replica at a time, by holding a
+// session level advisory lock on a dedicated connection. When the leader dies its
+// session ends, Postgres releases the lock and another replica takes over.
+type LeaderElector struct {
+	db            *sqlx.DB
+	name          string
+	lockID        int64
+	checkInterval time.Duration
+	logger        *zap.Logger
+}
+
+// NewLeaderElector creates an elector for the lock lockID, name identifies it in logs and metrics.
+func NewLeaderElector(db *sqlx.DB, name string, lockID int64, checkInterval time.Duration, logger *zap.Logger) *LeaderElector {
+	return &LeaderElector{
+		db:            db,
+		name:          name,
+		lockID:        lockID,
+		checkInterval: checkInterval,
+		logger:        logger.Named("LeaderElector").With(zap.String("election", name)),
+	}
+}
+
+// Run tries to become the leader every check interval and runs fn while it is.
+// The context passed to fn is cancelled once leadership is lost. Run returns
+// when ctx is done.
+func (e *LeaderElector) Run(ctx context.Context, fn func(ctx context.Context)) {
+	for {
+		conn, err := e.acquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Error("Failed to acquire leadership:", zap.Error(err))
+		}
+		if conn != nil {
+			e.lead(ctx, conn, fn)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.checkInterval):
+		}
+	}
+}
+
+// acquire returns a connection holding the lock, or nil when another replica holds it.
+func (e *LeaderElector) acquire(ctx context.Context) (*sql.Conn, error) {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, e.lockID).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, nil
+	}
+
+	return conn, nil
+}
+
+func (e *LeaderElector) lead(ctx context.Context, conn *sql.Conn, fn func(ctx context.Context)) {
+	e.logger.Info("Acquired leadership")
+	metrics.LeaderElected.WithLabelValues(e.name).Set(1)
+	defer metrics.LeaderElected.WithLabelValues(e.name).Set(0)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+
+	for lost := false; !lost; {
+		select {
+		case <-ctx.Done():
+			lost = true
+		case <-done:
+			lost = true
+		case <-ticker.C:
+			// The lock lives as long as the session, so a healthy connection means we still hold it.
+			if err := e.ping(ctx, conn); err != nil {
+				e.logger.Warn("Lost leadership:", zap.Error(err))
+				lost = true
+			}
+		}
+	}
+
+	cancel()
+	<-done
+	e.release(conn)
+}
+
+func (e *LeaderElector) ping(ctx context.Context, conn *sql.Conn) error {
+	ctx, cancel := context.WithTimeout(ctx, e.checkInterval)
+	defer cancel()
+
+	_, err := conn.ExecContext(ctx, `SELECT 1`)
+	return err
+}
+
+// release unlocks and closes conn. If unlocking fails the connection is
+// discarded instead of going back to the pool with the lock still held.
+func (e *LeaderElector) release(conn *sql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, e.lockID); err != nil {
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+	e.logger.Info("Released leadership")
+}
@@ -0,0 +1,58 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// OutboxChannel is notified by the trigger on outbox inserts.
+const OutboxChannel = "outbox"
+
+// ListenOutbox returns a channel that receives a value whenever rows are
+// inserted into the outbox, until ctx is done. Reconnects are signalled too,
+// since notifications may have been missed while disconnected. Notifications
+// are coalesced, so a reader only learns that there is something to publish.
+func ListenOutbox(ctx context.Context, dsn string, logger *zap.Logger) <-chan struct{} {
+	logger = logger.Named("OutboxListener")
+	wake := make(chan struct{}, 1)
+
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Listener connection problem:", zap.Error(err))
+		}
+	})
+
+	go func() {
+		// Listen blocks until the connection is established.
+		if err := listener.Listen(OutboxChannel); err != nil && ctx.Err() == nil {
+			logger.Error("Failed to listen for outbox notifications:", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		defer listener.Close()
+
+		// Pinging detects dead connections that would otherwise go unnoticed.
+		ping := time.NewTicker(time.Minute)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-listener.Notify:
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			case <-ping.C:
+				go listener.Ping()
+			}
+		}
+	}()
+
+	return wake
+}
@@ -0,0 +1,167 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"products/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+type outboxRow struct {
+	ID          int64     `db:"id"`
+	TenantID    string    `db:"tenant_id"`
+	AggregateID string    `db:"aggregate_id"`
+	EventType   string    `db:"event_type"`
+	Topic       string    `db:"topic"`
+	Payload     []byte    `db:"payload"`
+	Headers     []byte    `db:"headers"`
+	Attempts    int       `db:"attempts"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (r *outboxRow) toModel() (models.OutboxMessage, error) {
+	msg := models.OutboxMessage{
+		ID:          r.ID,
+		TenantID:    r.TenantID,
+		AggregateID: r.AggregateID,
+		EventType:   r.EventType,
+		Topic:       r.Topic,
+		Payload:     r.Payload,
+		Attempts:    r.Attempts,
+		CreatedAt:   r.CreatedAt,
+	}
+	if err := json.Unmarshal(r.Headers, &msg.Headers); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// Enqueue stores msg in the outbox, within the transaction from ctx if there is one.
+func (r *OutboxRepository) Enqueue(ctx context.Context, msg *models.OutboxMessage) (err error) {
+	ctx, end := instrument(ctx, "OutboxRepository.Enqueue")
+	defer end(&err)
+
+	headers := msg.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+
+	var query = `
+		INSERT INTO outbox (tenant_id, aggregate_id, event_type, topic, payload, headers)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, msg.TenantID, msg.AggregateID, msg.EventType, msg.Topic, msg.Payload, encodedHeaders)
+	return err
+}
+
+// Claim returns up to limit messages that are due, never more than the oldest
+// pending one per aggregate so aggregates are published in order, and postpones
+// their next attempt by lease so they aren't claimed again while they are sent.
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) (_ []models.OutboxMessage, err error) {
+	ctx, end := instrument(ctx, "OutboxRepository.Claim")
+	defer end(&err)
+
+	var query = `
+		WITH claimed AS (
+		  UPDATE outbox SET next_attempt_at = NOW() + make_interval(secs => $2)
+		  WHERE id IN (
+		    SELECT o.id FROM outbox o
+		    WHERE o.sent_at IS NULL AND o.next_attempt_at <= NOW()
+		      AND NOT EXISTS (
+		        SELECT 1 FROM outbox earlier
+		        WHERE earlier.aggregate_id = o.aggregate_id AND earlier.sent_at IS NULL AND earlier.id < o.id
+		      )
+		    ORDER BY o.id
+		    LIMIT $1
+		    FOR UPDATE SKIP LOCKED
+		  )
+		  RETURNING id, tenant_id, aggregate_id, event_type, topic, payload, headers, attempts, created_at
+		)
+		SELECT * FROM claimed ORDER BY id
+	`
+	var rows []outboxRow
+	err = conn(ctx, r.db).SelectContext(ctx, &rows, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]models.OutboxMessage, 0, len(rows))
+	for i := range rows {
+		msg, err := rows[i].toModel()
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id int64) (err error) {
+	ctx, end := instrument(ctx, "OutboxRepository.MarkSent")
+	defer end(&err)
+
+	var query = `
+		UPDATE outbox SET sent_at = NOW(), attempts = attempts + 1, last_error = NULL
+		WHERE id = $1
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkFailed records a failed publish attempt and postpones the next one until nextAttemptAt.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) (err error) {
+	ctx, end := instrument(ctx, "OutboxRepository.MarkFailed")
+	defer end(&err)
+
+	var query = `
+		UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, id, reason, nextAttemptAt)
+	return err
+}
+
+// DeleteSent removes messages sent before the given time and returns how many were removed.
+func (r *OutboxRepository) DeleteSent(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, end := instrument(ctx, "OutboxRepository.DeleteSent")
+	defer end(&err)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM outbox WHERE sent_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *OutboxRepository) Stats(ctx context.Context) (_ models.OutboxStats, err error) {
+	ctx, end := instrument(ctx, "OutboxRepository.Stats")
+	defer end(&err)
+
+	var row struct {
+		Pending int          `db:"pending"`
+		Oldest  sql.NullTime `db:"oldest"`
+	}
+	err = conn(ctx, r.db).GetContext(ctx, &row, `SELECT COUNT(*) AS pending, MIN(created_at) AS oldest FROM outbox WHERE sent_at IS NULL`)
+	if err != nil {
+		return models.OutboxStats{}, err
+	}
+
+	return models.OutboxStats{Pending: row.Pending, Oldest: row.Oldest.Time}, nil
+}
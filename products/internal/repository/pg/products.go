@@ -19,26 +19,36 @@ func NewProductsRepository(db *sqlx.DB) *ProductsRepository {
 	}
 }
 
-func (r *ProductsRepository) Create(ctx context.Context, createDTO *models.CreateProductDTO) (*models.Product, error) {
+func (r *ProductsRepository) Create(ctx context.Context, createDTO *models.CreateProductDTO) (_ *models.Product, err error) {
+	ctx, end := instrument(ctx, "ProductsRepository.Create")
+	defer end(&err)
+
 	var query = `
-		INSERT INTO products (name, description, price) 
-		VALUES ($1, $2, $3) 
+		INSERT INTO products (tenant_id, name, description, price)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, name, description, price, created_at
 	`
 	var product models.Product
-	err := r.db.GetContext(ctx, &product, query, createDTO.Name, createDTO.Description, createDTO.Price)
+	err = withTenant(ctx, r.db, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.GetContext(ctx, &product, query, tenantID, createDTO.Name, createDTO.Description, createDTO.Price)
+	})
 
 	return &product, err
 }
 
-func (r *ProductsRepository) Delete(ctx context.Context, id string) (*models.Product, error) {
+func (r *ProductsRepository) Delete(ctx context.Context, id string) (_ *models.Product, err error) {
+	ctx, end := instrument(ctx, "ProductsRepository.Delete")
+	defer end(&err)
+
 	var query = `
-		DELETE FROM products 
-		WHERE id = $1
+		DELETE FROM products
+		WHERE tenant_id = $1 AND id = $2
 		RETURNING id, name, description, price, created_at
 	`
 	var product models.Product
-	err := r.db.GetContext(ctx, &product, query, id)
+	err = withTenant(ctx, r.db, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.GetContext(ctx, &product, query, tenantID, id)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -50,16 +60,22 @@ func (r *ProductsRepository) Delete(ctx context.Context, id string) (*models.Pro
 	return &product, err
 }
 
-func (r *ProductsRepository) List(ctx context.Context, listDTO *models.ListProductsDTO) ([]models.Product, error) {
+func (r *ProductsRepository) List(ctx context.Context, listDTO *models.ListProductsDTO) (_ []models.Product, err error) {
+	ctx, end := instrument(ctx, "ProductsRepository.List")
+	defer end(&err)
+
 	var query = `
-		SELECT id, name, description, price, created_at FROM products 
+		SELECT id, name, description, price, created_at FROM products
+		WHERE tenant_id = $1
 		ORDER BY created_at
-		LIMIT $1 OFFSET $2 
+		LIMIT $2 OFFSET $3
 	`
 
 	offset := (listDTO.Page - 1) * listDTO.Limit
 	var products []models.Product
-	err := r.db.SelectContext(ctx, &products, query, listDTO.Limit, offset)
+	err = withTenant(ctx, r.db, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.SelectContext(ctx, &products, query, tenantID, listDTO.Limit, offset)
+	})
 
 	if err != nil {
 		return nil, err
@@ -72,13 +88,19 @@ func (r *ProductsRepository) List(ctx context.Context, listDTO *models.ListProdu
 	return products, err
 }
 
-func (r *ProductsRepository) Count(ctx context.Context) (int, error) {
+func (r *ProductsRepository) Count(ctx context.Context) (_ int, err error) {
+	ctx, end := instrument(ctx, "ProductsRepository.Count")
+	defer end(&err)
+
 	query := `
-		SELECT COUNT(*) 
-		FROM products 
+		SELECT COUNT(*)
+		FROM products
+		WHERE tenant_id = $1
 	`
 
 	var count int
-	err := r.db.GetContext(ctx, &count, query)
+	err = withTenant(ctx, r.db, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.GetContext(ctx, &count, query, tenantID)
+	})
 	return count, err
 }
@@ -0,0 +1,25 @@
+package pg
+
+import (
+	"context"
+	"products/internal/apperrors"
+	"products/internal/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// withTenant runs fn in a transaction with app.tenant_id set to the tenant from ctx,
+// which is what the row level security policies on tenant scoped tables check.
+// Queries still filter by tenant_id explicitly, the policy is only a safety net.
+// A transaction started by TxManager is reused.
+func withTenant(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx, tenantID string) error) error {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return apperrors.ErrTenantRequired
+	}
+
+	return NewTxManager(db).WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := txFromContext(ctx)
+		return fn(tx, tenantID)
+	})
+}
@@ -0,0 +1,71 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"products/internal/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type txKey struct{}
+
+// TxManager runs functions in a transaction that the repositories of this
+// package pick up from the context, so several repository calls commit or
+// roll back together.
+type TxManager struct {
+	db *sqlx.DB
+}
+
+func NewTxManager(db *sqlx.DB) *TxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+// WithinTx runs fn in a transaction, committing it when fn returns nil. When
+// ctx already carries a transaction fn joins it. The tenant from ctx, if any,
+// is set for the row level security policies.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		if _, err := tx.ExecContext(ctx, `SELECT set_config('app.tenant_id', $1, true)`, tenantID); err != nil {
+			return fmt.Errorf("failed to set tenant: %w", err)
+		}
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func txFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// querier is implemented by both *sqlx.DB and *sqlx.Tx.
+type querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// conn returns the transaction from ctx or db when there is none.
+func conn(ctx context.Context, db *sqlx.DB) querier {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
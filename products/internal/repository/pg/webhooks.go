@@ -0,0 +1,387 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"products/internal/apperrors"
+	"products/internal/models"
+	"products/internal/tenant"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const webhookSubscriptionColumns = `id, tenant_id, url, event_types, secret, active, consecutive_failures, disabled_reason, created_at, updated_at`
+
+type WebhooksRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhooksRepository(db *sqlx.DB) *WebhooksRepository {
+	return &WebhooksRepository{
+		db: db,
+	}
+}
+
+type webhookSubscriptionRow struct {
+	ID                  string         `db:"id"`
+	TenantID            string         `db:"tenant_id"`
+	URL                 string         `db:"url"`
+	EventTypes          pq.StringArray `db:"event_types"`
+	Secret              string         `db:"secret"`
+	Active              bool           `db:"active"`
+	ConsecutiveFailures int            `db:"consecutive_failures"`
+	DisabledReason      sql.NullString `db:"disabled_reason"`
+	CreatedAt           time.Time      `db:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at"`
+}
+
+func (r *webhookSubscriptionRow) toModel() *models.WebhookSubscription {
+	eventTypes := []string(r.EventTypes)
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return &models.WebhookSubscription{
+		ID:                  r.ID,
+		TenantID:            r.TenantID,
+		URL:                 r.URL,
+		EventTypes:          eventTypes,
+		Secret:              r.Secret,
+		Active:              r.Active,
+		ConsecutiveFailures: r.ConsecutiveFailures,
+		DisabledReason:      r.DisabledReason.String,
+		CreatedAt:           r.CreatedAt,
+		UpdatedAt:           r.UpdatedAt,
+	}
+}
+
+type webhookDeliveryRow struct {
+	ID             int64          `db:"id"`
+	SubscriptionID string         `db:"subscription_id"`
+	EventID        string         `db:"event_id"`
+	EventType      string         `db:"event_type"`
+	Payload        []byte         `db:"payload"`
+	Status         string         `db:"status"`
+	Attempts       int            `db:"attempts"`
+	ResponseStatus sql.NullInt64  `db:"response_status"`
+	LastError      sql.NullString `db:"last_error"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	DeliveredAt    *time.Time     `db:"delivered_at"`
+	URL            string         `db:"url"`
+	Secret         string         `db:"secret"`
+}
+
+func (r *webhookDeliveryRow) toModel() models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:             r.ID,
+		SubscriptionID: r.SubscriptionID,
+		EventID:        r.EventID,
+		EventType:      r.EventType,
+		Payload:        r.Payload,
+		Status:         models.WebhookDeliveryStatus(r.Status),
+		Attempts:       r.Attempts,
+		ResponseStatus: int(r.ResponseStatus.Int64),
+		LastError:      r.LastError.String,
+		NextAttemptAt:  r.NextAttemptAt,
+		CreatedAt:      r.CreatedAt,
+		DeliveredAt:    r.DeliveredAt,
+		URL:            r.URL,
+		Secret:         r.Secret,
+	}
+}
+
+func (r *WebhooksRepository) Create(ctx context.Context, sub *models.WebhookSubscription) (_ *models.WebhookSubscription, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.Create")
+	defer end(&err)
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTenantRequired
+	}
+
+	var query = `
+		INSERT INTO webhook_subscriptions (tenant_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookSubscriptionColumns
+	var row webhookSubscriptionRow
+	err = conn(ctx, r.db).GetContext(ctx, &row, query, tenantID, sub.URL, pq.StringArray(sub.EventTypes), sub.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *WebhooksRepository) Get(ctx context.Context, id string) (_ *models.WebhookSubscription, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.Get")
+	defer end(&err)
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTenantRequired
+	}
+
+	var query = `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE tenant_id = $1 AND id = $2`
+	var row webhookSubscriptionRow
+	err = conn(ctx, r.db).GetContext(ctx, &row, query, tenantID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+func (r *WebhooksRepository) List(ctx context.Context) (_ []models.WebhookSubscription, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.List")
+	defer end(&err)
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTenantRequired
+	}
+
+	var query = `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE tenant_id = $1 ORDER BY created_at`
+	return r.selectSubscriptions(ctx, query, tenantID)
+}
+
+// ListActive returns the active subscriptions of the tenant from ctx that receive eventType.
+func (r *WebhooksRepository) ListActive(ctx context.Context, eventType string) (_ []models.WebhookSubscription, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.ListActive")
+	defer end(&err)
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTenantRequired
+	}
+
+	var query = `
+		SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions
+		WHERE tenant_id = $1 AND active AND (event_types = '{}' OR $2 = ANY(event_types))
+		ORDER BY created_at
+	`
+	return r.selectSubscriptions(ctx, query, tenantID, eventType)
+}
+
+func (r *WebhooksRepository) selectSubscriptions(ctx context.Context, query string, args ...any) ([]models.WebhookSubscription, error) {
+	var rows []webhookSubscriptionRow
+	if err := conn(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.WebhookSubscription, 0, len(rows))
+	for i := range rows {
+		subs = append(subs, *rows[i].toModel())
+	}
+	return subs, nil
+}
+
+// Update changes the fields of update that are set. Activating a subscription
+// resets its failure count and disabled reason.
+func (r *WebhooksRepository) Update(ctx context.Context, id string, update *models.UpdateWebhookDTO) (_ *models.WebhookSubscription, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.Update")
+	defer end(&err)
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTenantRequired
+	}
+
+	var eventTypes pq.StringArray
+	if update.EventTypes != nil {
+		eventTypes = pq.StringArray(*update.EventTypes)
+		if eventTypes == nil {
+			eventTypes = pq.StringArray{}
+		}
+	}
+
+	var query = `
+		UPDATE webhook_subscriptions SET
+		  url = COALESCE($3::text, url),
+		  event_types = COALESCE($4::text[], event_types),
+		  active = COALESCE($5::boolean, active),
+		  consecutive_failures = CASE WHEN $5::boolean THEN 0 ELSE consecutive_failures END,
+		  disabled_reason = CASE WHEN $5::boolean THEN NULL ELSE disabled_reason END,
+		  updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING ` + webhookSubscriptionColumns
+	var row webhookSubscriptionRow
+	err = conn(ctx, r.db).GetContext(ctx, &row, query, tenantID, id, update.URL, eventTypes, update.Active)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+// Delete removes the subscription together with its deliveries.
+func (r *WebhooksRepository) Delete(ctx context.Context, id string) (_ *models.WebhookSubscription, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.Delete")
+	defer end(&err)
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTenantRequired
+	}
+
+	var query = `DELETE FROM webhook_subscriptions WHERE tenant_id = $1 AND id = $2 RETURNING ` + webhookSubscriptionColumns
+	var row webhookSubscriptionRow
+	err = conn(ctx, r.db).GetContext(ctx, &row, query, tenantID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return row.toModel(), nil
+}
+
+// CreateDeliveries stores pending deliveries, within the transaction from ctx if there is one.
+func (r *WebhooksRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) (err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.CreateDeliveries")
+	defer end(&err)
+
+	var query = `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, d := range deliveries {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, d.SubscriptionID, d.EventID, d.EventType, d.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliveries returns the latest deliveries of a subscription of the tenant from ctx, newest first.
+func (r *WebhooksRepository) Deliveries(ctx context.Context, subscriptionID string, limit int) (_ []models.WebhookDelivery, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.Deliveries")
+	defer end(&err)
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrTenantRequired
+	}
+
+	var query = `
+		SELECT d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+		       d.response_status, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at, s.url, s.secret
+		FROM webhook_deliveries d JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE s.tenant_id = $1 AND d.subscription_id = $2
+		ORDER BY d.id DESC
+		LIMIT $3
+	`
+	return r.selectDeliveries(ctx, query, tenantID, subscriptionID, limit)
+}
+
+// Claim returns up to limit due deliveries of active subscriptions and postpones
+// their next attempt by lease, so other dispatchers skip them while they are sent.
+func (r *WebhooksRepository) Claim(ctx context.Context, limit int, lease time.Duration) (_ []models.WebhookDelivery, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.Claim")
+	defer end(&err)
+
+	var query = `
+		UPDATE webhook_deliveries d SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+		  SELECT pending.id FROM webhook_deliveries pending
+		  JOIN webhook_subscriptions sub ON sub.id = pending.subscription_id
+		  WHERE pending.status = 'pending' AND pending.next_attempt_at <= NOW() AND sub.active
+		  ORDER BY pending.id
+		  LIMIT $1
+		  FOR UPDATE OF pending SKIP LOCKED
+		)
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+		          d.response_status, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at, s.url, s.secret
+	`
+	return r.selectDeliveries(ctx, query, limit, lease.Seconds())
+}
+
+func (r *WebhooksRepository) selectDeliveries(ctx context.Context, query string, args ...any) ([]models.WebhookDelivery, error) {
+	var rows []webhookDeliveryRow
+	if err := conn(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(rows))
+	for i := range rows {
+		deliveries = append(deliveries, rows[i].toModel())
+	}
+	return deliveries, nil
+}
+
+// MarkDelivered records a successful attempt and resets the subscription's failure count.
+func (r *WebhooksRepository) MarkDelivered(ctx context.Context, id int64, responseStatus int) (err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.MarkDelivered")
+	defer end(&err)
+
+	var query = `
+		WITH delivery AS (
+		  UPDATE webhook_deliveries SET status = 'delivered', attempts = attempts + 1,
+		    response_status = $2, last_error = NULL, delivered_at = NOW()
+		  WHERE id = $1
+		  RETURNING subscription_id
+		)
+		UPDATE webhook_subscriptions SET consecutive_failures = 0
+		WHERE id = (SELECT subscription_id FROM delivery)
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, id, responseStatus)
+	return err
+}
+
+// MarkFailed records a failed attempt, which fails the delivery for good when
+// attempt.NextAttemptAt is nil. The subscription is disabled once it reaches
+// disableAfter consecutive failures, MarkFailed reports whether this attempt disabled it.
+func (r *WebhooksRepository) MarkFailed(ctx context.Context, id int64, attempt models.WebhookAttempt, disableAfter int) (_ bool, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.MarkFailed")
+	defer end(&err)
+
+	var query = `
+		WITH delivery AS (
+		  UPDATE webhook_deliveries SET attempts = attempts + 1,
+		    response_status = NULLIF($2, 0), last_error = $3,
+		    status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		    next_attempt_at = COALESCE($4::timestamptz, next_attempt_at)
+		  WHERE id = $1
+		  RETURNING subscription_id
+		)
+		UPDATE webhook_subscriptions s SET
+		  consecutive_failures = s.consecutive_failures + 1,
+		  active = s.active AND s.consecutive_failures + 1 < $5,
+		  disabled_reason = CASE WHEN s.active AND s.consecutive_failures + 1 >= $5
+		    THEN 'disabled after ' || (s.consecutive_failures + 1) || ' consecutive failed deliveries'
+		    ELSE s.disabled_reason END,
+		  updated_at = NOW()
+		FROM delivery
+		WHERE s.id = delivery.subscription_id
+		RETURNING NOT s.active AND s.consecutive_failures = $5
+	`
+	var disabled bool
+	err = conn(ctx, r.db).GetContext(ctx, &disabled, query, id, attempt.ResponseStatus, attempt.Error, attempt.NextAttemptAt, disableAfter)
+	if err == sql.ErrNoRows {
+		// The subscription was deleted while the delivery was sent.
+		return false, nil
+	}
+	return disabled, err
+}
+
+// DeleteFinished removes delivered and failed deliveries created before the given time.
+func (r *WebhooksRepository) DeleteFinished(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, end := instrument(ctx, "WebhooksRepository.DeleteFinished")
+	defer end(&err)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,44 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Header = "X-Request-ID"
+	// MessageHeader carries the request ID on broker messages.
+	MessageHeader = "request-id"
+
+	maxLength = 128
+)
+
+type requestIDKey struct{}
+
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether an ID received from a client can be reused as is.
+// IDs end up in logs and message headers, so only short printable ASCII is accepted.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,22 @@
+package serde
+
+import "contracts/productevent"
+
+var AvroSchema = Schema{Type: SchemaTypeAvro, Schema: productevent.AvroSchema}
+
+// AvroSerializer encodes events with the products.events.v1.ProductEvent record schema.
+type AvroSerializer struct {
+	schemaID int
+}
+
+func (s *AvroSerializer) Serialize(event *productevent.Event) ([]byte, error) {
+	payload, err := productevent.EncodeAvro(event)
+	if err != nil {
+		return nil, err
+	}
+	return append(appendWireHeader(make([]byte, 0, 5+len(payload)), s.schemaID), payload...), nil
+}
+
+func (s *AvroSerializer) ContentType() string {
+	return productevent.AvroContentType
+}
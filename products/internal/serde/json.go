@@ -0,0 +1,14 @@
+package serde
+
+import "contracts/productevent"
+
+// JSONSerializer writes structured mode CloudEvents. It does not use the schema registry.
+type JSONSerializer struct{}
+
+func (JSONSerializer) Serialize(event *productevent.Event) ([]byte, error) {
+	return productevent.EncodeJSON(event)
+}
+
+func (JSONSerializer) ContentType() string {
+	return productevent.JSONContentType
+}
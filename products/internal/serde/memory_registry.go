@@ -0,0 +1,231 @@
+package serde
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// MemoryRegistry is an in-process schema registry for development and tests.
+// It enforces BACKWARD compatibility like the Confluent default: a new version
+// must be able to read data written with the latest one. Handler serves it over
+// the Confluent REST API.
+type MemoryRegistry struct {
+	mu       sync.Mutex
+	schemas  []Schema
+	subjects map[string][]int
+}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		subjects: map[string][]int{},
+	}
+}
+
+func (r *MemoryRegistry) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range r.subjects[subject] {
+		if r.schemas[id-1] == schema {
+			return id, nil
+		}
+	}
+
+	if err := r.checkCompatible(subject, schema); err != nil {
+		return 0, err
+	}
+
+	r.schemas = append(r.schemas, schema)
+	id := len(r.schemas)
+	r.subjects[subject] = append(r.subjects[subject], id)
+	return id, nil
+}
+
+func (r *MemoryRegistry) Compatible(ctx context.Context, subject string, schema Schema) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.checkCompatible(subject, schema)
+	if errors.Is(err, ErrIncompatibleSchema) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *MemoryRegistry) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.schemas) {
+		return Schema{}, fmt.Errorf("%w: id %d", ErrSchemaNotFound, id)
+	}
+	return r.schemas[id-1], nil
+}
+
+func (r *MemoryRegistry) checkCompatible(subject string, schema Schema) error {
+	versions := r.subjects[subject]
+	if len(versions) == 0 {
+		return nil
+	}
+	latest := r.schemas[versions[len(versions)-1]-1]
+
+	if latest.Type != schema.Type {
+		return fmt.Errorf("%w: schema type changed from %s to %s", ErrIncompatibleSchema, latest.Type, schema.Type)
+	}
+
+	switch schema.Type {
+	case SchemaTypeAvro:
+		writer, err := avro.Parse(latest.Schema)
+		if err != nil {
+			return err
+		}
+		reader, err := avro.Parse(schema.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid avro schema: %w", err)
+		}
+		if err := avro.NewSchemaCompatibility().Compatible(reader, writer); err != nil {
+			return fmt.Errorf("%w: %w", ErrIncompatibleSchema, err)
+		}
+	case SchemaTypeProtobuf:
+		if err := protoCompatible(latest.Schema, schema.Schema); err != nil {
+			return fmt.Errorf("%w: %w", ErrIncompatibleSchema, err)
+		}
+	}
+	return nil
+}
+
+var (
+	protoMessage = regexp.MustCompile(`^message\s+(\w+)\s*\{`)
+	protoField   = regexp.MustCompile(`^(?:repeated\s+|optional\s+)?([\w.]+)\s+(\w+)\s*=\s*(\d+)\s*;`)
+)
+
+// protoFields maps "Message.number" to the field type for the top level
+// messages of a proto file. Nested messages are not supported.
+func protoFields(schema string) map[string]string {
+	fields := map[string]string{}
+	var message string
+	for _, line := range strings.Split(schema, "\n") {
+		line = strings.TrimSpace(line)
+		if m := protoMessage.FindStringSubmatch(line); m != nil {
+			message = m[1]
+			continue
+		}
+		if line == "}" {
+			message = ""
+			continue
+		}
+		if m := protoField.FindStringSubmatch(line); m != nil && message != "" {
+			fields[message+"."+m[3]] = m[1]
+		}
+	}
+	return fields
+}
+
+// protoCompatible rejects changing the type of an existing field number, the
+// change that makes old and new messages decode into garbage. Adding and
+// removing fields is fine in proto3.
+func protoCompatible(old, new string) error {
+	newFields := protoFields(new)
+	for field, oldType := range protoFields(old) {
+		if newType, ok := newFields[field]; ok && newType != oldType {
+			return fmt.Errorf("field %s changed type from %s to %s", field, oldType, newType)
+		}
+	}
+	return nil
+}
+
+// Handler serves the registry over the subset of the Confluent REST API that
+// HTTPRegistry uses.
+func (r *MemoryRegistry) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /subjects/{subject}/versions", func(w http.ResponseWriter, req *http.Request) {
+		schema, ok := decodeSchemaRequest(w, req)
+		if !ok {
+			return
+		}
+		id, err := r.Register(req.Context(), req.PathValue("subject"), schema)
+		if errors.Is(err, ErrIncompatibleSchema) {
+			writeRegistryError(w, http.StatusConflict, 409, err.Error())
+			return
+		}
+		if err != nil {
+			writeRegistryError(w, http.StatusUnprocessableEntity, 42201, err.Error())
+			return
+		}
+		writeRegistryJSON(w, map[string]int{"id": id})
+	})
+
+	mux.HandleFunc("POST /compatibility/subjects/{subject}/versions/latest", func(w http.ResponseWriter, req *http.Request) {
+		schema, ok := decodeSchemaRequest(w, req)
+		if !ok {
+			return
+		}
+		subject := req.PathValue("subject")
+		r.mu.Lock()
+		_, exists := r.subjects[subject]
+		r.mu.Unlock()
+		if !exists {
+			writeRegistryError(w, http.StatusNotFound, errorCodeSubjectNotFound, "Subject not found")
+			return
+		}
+		compatible, err := r.Compatible(req.Context(), subject, schema)
+		if err != nil {
+			writeRegistryError(w, http.StatusUnprocessableEntity, 42201, err.Error())
+			return
+		}
+		writeRegistryJSON(w, map[string]bool{"is_compatible": compatible})
+	})
+
+	mux.HandleFunc("GET /schemas/ids/{id}", func(w http.ResponseWriter, req *http.Request) {
+		id, err := strconv.Atoi(req.PathValue("id"))
+		if err != nil {
+			writeRegistryError(w, http.StatusNotFound, errorCodeSchemaNotFound, "Schema not found")
+			return
+		}
+		schema, err := r.SchemaByID(req.Context(), id)
+		if err != nil {
+			writeRegistryError(w, http.StatusNotFound, errorCodeSchemaNotFound, "Schema not found")
+			return
+		}
+		resp := schemaRequest{Schema: schema.Schema, SchemaType: schema.Type}
+		if resp.SchemaType == SchemaTypeAvro {
+			resp.SchemaType = ""
+		}
+		writeRegistryJSON(w, resp)
+	})
+
+	return mux
+}
+
+func decodeSchemaRequest(w http.ResponseWriter, req *http.Request) (Schema, bool) {
+	var body schemaRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeRegistryError(w, http.StatusUnprocessableEntity, 42201, "Invalid schema")
+		return Schema{}, false
+	}
+	if body.SchemaType == "" {
+		body.SchemaType = SchemaTypeAvro
+	}
+	return Schema{Type: body.SchemaType, Schema: body.Schema}, true
+}
+
+func writeRegistryJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeRegistryError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(registryError{ErrorCode: code, Message: message})
+}
@@ -0,0 +1,26 @@
+package serde
+
+import "contracts/productevent"
+
+var ProtobufSchema = Schema{Type: SchemaTypeProtobuf, Schema: productevent.ProtobufSchema}
+
+// ProtobufSerializer encodes events as the products.events.v1.ProductEvent message.
+type ProtobufSerializer struct {
+	schemaID int
+}
+
+func (s *ProtobufSerializer) Serialize(event *productevent.Event) ([]byte, error) {
+	message, err := productevent.EncodeProtobuf(event)
+	if err != nil {
+		return nil, err
+	}
+
+	b := appendWireHeader(make([]byte, 0, 6+len(message)), s.schemaID)
+	// Message indexes of the encoded message, [0] (the first message in the file) is written as a single 0.
+	b = append(b, 0)
+	return append(b, message...), nil
+}
+
+func (s *ProtobufSerializer) ContentType() string {
+	return productevent.ProtobufContentType
+}
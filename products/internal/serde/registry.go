@@ -0,0 +1,165 @@
+package serde
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type SchemaType string
+
+const (
+	SchemaTypeAvro     SchemaType = "AVRO"
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+	SchemaTypeJSON     SchemaType = "JSON"
+)
+
+type Schema struct {
+	Type   SchemaType
+	Schema string
+}
+
+var (
+	ErrSchemaNotFound     = errors.New("schema not found")
+	ErrIncompatibleSchema = errors.New("schema is incompatible with the latest registered version")
+)
+
+// Registry is the subset of the Confluent Schema Registry API the serializers use.
+type Registry interface {
+	// Register adds schema to subject, or returns the id it is already registered with.
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+	// Compatible reports whether schema can be registered as the next version of
+	// subject. A subject without versions accepts any schema.
+	Compatible(ctx context.Context, subject string, schema Schema) (bool, error)
+	SchemaByID(ctx context.Context, id int) (Schema, error)
+}
+
+// HTTPRegistry talks to a Confluent compatible schema registry.
+type HTTPRegistry struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPRegistry(baseURL string) *HTTPRegistry {
+	return &HTTPRegistry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Error codes of the schema registry API.
+const (
+	errorCodeSubjectNotFound = 40401
+	errorCodeVersionNotFound = 40402
+	errorCodeSchemaNotFound  = 40403
+)
+
+type registryError struct {
+	StatusCode int    `json:"-"`
+	ErrorCode  int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("schema registry: %d %s", e.ErrorCode, e.Message)
+}
+
+type schemaRequest struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+}
+
+func newSchemaRequest(schema Schema) schemaRequest {
+	req := schemaRequest{Schema: schema.Schema, SchemaType: schema.Type}
+	// AVRO is the default and older registries reject the field.
+	if req.SchemaType == SchemaTypeAvro {
+		req.SchemaType = ""
+	}
+	return req
+}
+
+func (r *HTTPRegistry) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	var resp struct {
+		ID int `json:"id"`
+	}
+	err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", newSchemaRequest(schema), &resp)
+	if err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+func (r *HTTPRegistry) Compatible(ctx context.Context, subject string, schema Schema) (bool, error) {
+	var resp struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	err := r.do(ctx, http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest", newSchemaRequest(schema), &resp)
+
+	var regErr *registryError
+	if errors.As(err, &regErr) && (regErr.ErrorCode == errorCodeSubjectNotFound || regErr.ErrorCode == errorCodeVersionNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return resp.IsCompatible, nil
+}
+
+func (r *HTTPRegistry) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	var resp struct {
+		Schema     string     `json:"schema"`
+		SchemaType SchemaType `json:"schemaType"`
+	}
+	err := r.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp)
+
+	var regErr *registryError
+	if errors.As(err, &regErr) && regErr.ErrorCode == errorCodeSchemaNotFound {
+		return Schema{}, fmt.Errorf("%w: id %d", ErrSchemaNotFound, id)
+	}
+	if err != nil {
+		return Schema{}, err
+	}
+
+	if resp.SchemaType == "" {
+		resp.SchemaType = SchemaTypeAvro
+	}
+	return Schema{Type: resp.SchemaType, Schema: resp.Schema}, nil
+}
+
+func (r *HTTPRegistry) do(ctx context.Context, method, path string, body, result any) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		regErr := &registryError{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(regErr); err != nil {
+			regErr.Message = resp.Status
+		}
+		return regErr
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,106 @@
+package serde
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRegistry(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(NewMemoryRegistry().Handler())
+	defer server.Close()
+
+	registry := NewHTTPRegistry(server.URL)
+
+	compatible, err := registry.Compatible(ctx, "product-events-value", ProtobufSchema)
+	require.NoError(t, err)
+	assert.True(t, compatible, "A new subject should accept any schema")
+
+	id, err := registry.Register(ctx, "product-events-value", ProtobufSchema)
+	require.NoError(t, err)
+
+	again, err := registry.Register(ctx, "product-events-value", ProtobufSchema)
+	require.NoError(t, err)
+	assert.Equal(t, id, again, "Registering the same schema should return its id")
+
+	schema, err := registry.SchemaByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, ProtobufSchema, schema)
+
+	avroID, err := registry.Register(ctx, "product-events-avro-value", AvroSchema)
+	require.NoError(t, err)
+	schema, err = registry.SchemaByID(ctx, avroID)
+	require.NoError(t, err)
+	assert.Equal(t, AvroSchema, schema)
+
+	_, err = registry.SchemaByID(ctx, 100)
+	assert.ErrorIs(t, err, ErrSchemaNotFound)
+}
+
+func TestMemoryRegistryCompatibility(t *testing.T) {
+	ctx := context.Background()
+
+	type testCase struct {
+		name       string
+		old        Schema
+		new        Schema
+		compatible bool
+	}
+
+	cases := []testCase{
+		{
+			name:       "Avro Field With Default Added",
+			old:        Schema{Type: SchemaTypeAvro, Schema: `{"type": "record", "name": "E", "fields": [{"name": "id", "type": "string"}]}`},
+			new:        Schema{Type: SchemaTypeAvro, Schema: `{"type": "record", "name": "E", "fields": [{"name": "id", "type": "string"}, {"name": "actor", "type": "string", "default": ""}]}`},
+			compatible: true,
+		},
+		{
+			name:       "Avro Field Without Default Added",
+			old:        Schema{Type: SchemaTypeAvro, Schema: `{"type": "record", "name": "E", "fields": [{"name": "id", "type": "string"}]}`},
+			new:        Schema{Type: SchemaTypeAvro, Schema: `{"type": "record", "name": "E", "fields": [{"name": "id", "type": "string"}, {"name": "actor", "type": "string"}]}`},
+			compatible: false,
+		},
+		{
+			name:       "Protobuf Field Added",
+			old:        Schema{Type: SchemaTypeProtobuf, Schema: "message E {\n  string id = 1;\n}\n"},
+			new:        Schema{Type: SchemaTypeProtobuf, Schema: "message E {\n  string id = 1;\n  string actor = 2;\n}\n"},
+			compatible: true,
+		},
+		{
+			name:       "Protobuf Field Type Changed",
+			old:        Schema{Type: SchemaTypeProtobuf, Schema: "message E {\n  string id = 1;\n}\n"},
+			new:        Schema{Type: SchemaTypeProtobuf, Schema: "message E {\n  int64 id = 1;\n}\n"},
+			compatible: false,
+		},
+		{
+			name:       "Schema Type Changed",
+			old:        AvroSchema,
+			new:        ProtobufSchema,
+			compatible: false,
+		},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			registry := NewMemoryRegistry()
+			_, err := registry.Register(ctx, "subject", tCase.old)
+			require.NoError(t, err)
+
+			compatible, err := registry.Compatible(ctx, "subject", tCase.new)
+
+			require.NoError(t, err)
+			assert.Equal(t, tCase.compatible, compatible)
+
+			_, err = registry.Register(ctx, "subject", tCase.new)
+			if tCase.compatible {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrIncompatibleSchema)
+			}
+		})
+	}
+}
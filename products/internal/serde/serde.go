@@ -0,0 +1,62 @@
+// Package serde serializes product events for the message broker as JSON
+// CloudEvents, or as Protobuf or Avro in the Confluent wire format with their
+// schemas kept in a schema registry.
+package serde
+
+import (
+	"context"
+	"contracts/productevent"
+	"fmt"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+	FormatAvro     = "avro"
+)
+
+type Serializer interface {
+	Serialize(event *productevent.Event) ([]byte, error)
+	ContentType() string
+}
+
+// NewSerializer returns the serializer for format. Protobuf and Avro check that
+// their schema is compatible with the latest version registered under subject
+// and register it, so an incompatible change fails at startup instead of
+// breaking consumers.
+func NewSerializer(ctx context.Context, format string, registry Registry, subject string) (Serializer, error) {
+	switch format {
+	case FormatJSON:
+		return JSONSerializer{}, nil
+	case FormatProtobuf:
+		id, err := registerSchema(ctx, registry, subject, ProtobufSchema)
+		if err != nil {
+			return nil, err
+		}
+		return &ProtobufSerializer{schemaID: id}, nil
+	case FormatAvro:
+		id, err := registerSchema(ctx, registry, subject, AvroSchema)
+		if err != nil {
+			return nil, err
+		}
+		return &AvroSerializer{schemaID: id}, nil
+	default:
+		return nil, fmt.Errorf("unknown event format %q", format)
+	}
+}
+
+func registerSchema(ctx context.Context, registry Registry, subject string, schema Schema) (int, error) {
+	compatible, err := registry.Compatible(ctx, subject, schema)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check schema compatibility: %w", err)
+	}
+	if !compatible {
+		return 0, fmt.Errorf("%w: subject %s", ErrIncompatibleSchema, subject)
+	}
+
+	id, err := registry.Register(ctx, subject, schema)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema: %w", err)
+	}
+	return id, nil
+}
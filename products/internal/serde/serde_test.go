@@ -0,0 +1,87 @@
+package serde
+
+import (
+	"context"
+	"contracts/productevent"
+	"contracts/productevent/eventtest"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The serializers must write the golden encodings of the contract, which the
+// notifications tests decode.
+func TestSerializers(t *testing.T) {
+	type testCase struct {
+		format string
+		golden []byte
+		// header is the length of the wire format prefix, followed by the encoded event.
+		header     int
+		schemaType SchemaType
+	}
+
+	cases := []testCase{
+		{format: FormatJSON, golden: eventtest.JSON},
+		{format: FormatProtobuf, golden: eventtest.Protobuf, header: 6, schemaType: SchemaTypeProtobuf},
+		{format: FormatAvro, golden: eventtest.Avro, header: 5, schemaType: SchemaTypeAvro},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.format, func(t *testing.T) {
+			registry := NewMemoryRegistry()
+			serializer, err := NewSerializer(context.Background(), tCase.format, registry, "product-events-value")
+			require.NoError(t, err)
+
+			msg, err := serializer.Serialize(eventtest.Event())
+			require.NoError(t, err)
+
+			assert.Equal(t, tCase.golden, msg[tCase.header:])
+			if tCase.header == 0 {
+				return
+			}
+			require.Equal(t, magicByte, msg[0])
+			schema, err := registry.SchemaByID(context.Background(), int(binary.BigEndian.Uint32(msg[1:5])))
+			require.NoError(t, err)
+			assert.Equal(t, tCase.schemaType, schema.Type)
+		})
+	}
+
+	t.Run("Protobuf Message Index", func(t *testing.T) {
+		serializer, err := NewSerializer(context.Background(), FormatProtobuf, NewMemoryRegistry(), "product-events-value")
+		require.NoError(t, err)
+
+		msg, err := serializer.Serialize(eventtest.Event())
+		require.NoError(t, err)
+
+		assert.Equal(t, byte(0), msg[5], "The first message in the schema should be referenced")
+	})
+}
+
+func TestSerializeInvalidEvent(t *testing.T) {
+	event := eventtest.Event()
+	event.Product.ID = ""
+
+	_, err := JSONSerializer{}.Serialize(event)
+
+	assert.ErrorIs(t, err, productevent.ErrInvalidEvent)
+}
+
+func TestNewSerializerIncompatibleSchema(t *testing.T) {
+	registry := NewMemoryRegistry()
+	_, err := registry.Register(context.Background(), "product-events-value", Schema{
+		Type:   SchemaTypeAvro,
+		Schema: `{"type": "record", "name": "ProductEvent", "namespace": "products.events.v1", "fields": [{"name": "id", "type": "long"}]}`,
+	})
+	require.NoError(t, err)
+
+	_, err = NewSerializer(context.Background(), FormatAvro, registry, "product-events-value")
+
+	assert.ErrorIs(t, err, ErrIncompatibleSchema)
+}
+
+func TestNewSerializerUnknownFormat(t *testing.T) {
+	_, err := NewSerializer(context.Background(), "xml", NewMemoryRegistry(), "product-events-value")
+	assert.Error(t, err)
+}
@@ -0,0 +1,12 @@
+package serde
+
+import "encoding/binary"
+
+// magicByte starts messages in the Confluent wire format, followed by the
+// schema id as a big endian uint32 and the payload.
+const magicByte byte = 0
+
+func appendWireHeader(b []byte, schemaID int) []byte {
+	b = append(b, magicByte)
+	return binary.BigEndian.AppendUint32(b, uint32(schemaID))
+}
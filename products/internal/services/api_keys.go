@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"products/internal/apperrors"
+	"products/internal/models"
+	"products/internal/tenant"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const apiKeyPrefix = "pk_"
+
+type APIKeysRepository interface {
+	Create(ctx context.Context, key *models.APIKey) (*models.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id string) (*models.APIKey, error)
+}
+
+type APIKeysService struct {
+	repo   APIKeysRepository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewAPIKeysService(repo APIKeysRepository, logger *zap.Logger) *APIKeysService {
+	return &APIKeysService{
+		repo:   repo,
+		logger: logger.Named("APIKeysService"),
+		now:    time.Now,
+	}
+}
+
+// Create stores a new API key and returns it together with the raw key.
+// The raw key is only available here, afterwards only its hash is known.
+func (s *APIKeysService) Create(ctx context.Context, createDTO *models.CreateAPIKeyDTO) (*models.APIKey, string, error) {
+	if createDTO.Label == "" {
+		return nil, "", fmt.Errorf("api key label is required")
+	}
+	if len(createDTO.Scopes) == 0 {
+		return nil, "", fmt.Errorf("at least one api key scope is required")
+	}
+	for _, scope := range createDTO.Scopes {
+		if !scope.Valid() {
+			return nil, "", fmt.Errorf("unknown api key scope %q", scope)
+		}
+	}
+	if createDTO.TenantID != "" && !tenant.Valid(createDTO.TenantID) {
+		return nil, "", fmt.Errorf("invalid tenant id %q", createDTO.TenantID)
+	}
+
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := s.repo.Create(ctx, &models.APIKey{
+		Label:     createDTO.Label,
+		Scopes:    createDTO.Scopes,
+		TenantID:  createDTO.TenantID,
+		KeyHash:   HashAPIKey(rawKey),
+		ExpiresAt: createDTO.ExpiresAt,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, rawKey, nil
+}
+
+// Authenticate resolves a raw key to a stored, non revoked and non expired API key.
+func (s *APIKeysService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	key, err := s.repo.GetByHash(ctx, HashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAPIKeyNotFound) {
+			return nil, apperrors.ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+
+	if key.IsExpired(s.now()) {
+		return nil, apperrors.ErrAPIKeyExpired
+	}
+
+	return key, nil
+}
+
+func (s *APIKeysService) List(ctx context.Context) ([]models.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *APIKeysService) Revoke(ctx context.Context, id string) (*models.APIKey, error) {
+	return s.repo.Revoke(ctx, id)
+}
+
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"products/internal/apperrors"
+	"products/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockAPIKeysRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeysRepository) Create(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeysRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeysRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeysRepository) Revoke(ctx context.Context, id string) (*models.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func TestAPIKeysService(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockAPIKeysRepository)
+	service := NewAPIKeysService(mockRepo, zap.NewNop())
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	t.Run("Create", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			createDTO := &models.CreateAPIKeyDTO{Label: "importer", Scopes: []models.APIKeyScope{models.ScopeWrite}}
+
+			var stored *models.APIKey
+			mockRepo.On("Create", ctx, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*models.APIKey)
+			}).Return(&models.APIKey{ID: "key-1", Label: "importer", Scopes: createDTO.Scopes}, nil).Once()
+
+			key, rawKey, err := service.Create(ctx, createDTO)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "key-1", key.ID)
+			assert.True(t, strings.HasPrefix(rawKey, apiKeyPrefix))
+			assert.Equal(t, HashAPIKey(rawKey), stored.KeyHash, "Only the hash of the key should be stored")
+			assert.NotContains(t, stored.KeyHash, rawKey)
+			mockRepo.AssertExpectations(t)
+		})
+
+		t.Run("Unknown Scope", func(t *testing.T) {
+			createDTO := &models.CreateAPIKeyDTO{Label: "importer", Scopes: []models.APIKeyScope{"superuser"}}
+
+			key, rawKey, err := service.Create(ctx, createDTO)
+
+			assert.Error(t, err)
+			assert.Nil(t, key)
+			assert.Empty(t, rawKey)
+		})
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		rawKey := "pk_test"
+
+		t.Run("Success", func(t *testing.T) {
+			expiresAt := now.Add(time.Hour)
+			stored := &models.APIKey{ID: "key-1", Scopes: []models.APIKeyScope{models.ScopeRead}, ExpiresAt: &expiresAt}
+			mockRepo.On("GetByHash", ctx, HashAPIKey(rawKey)).Return(stored, nil).Once()
+
+			key, err := service.Authenticate(ctx, rawKey)
+
+			assert.NoError(t, err)
+			assert.Equal(t, stored, key)
+		})
+
+		t.Run("Unknown Key", func(t *testing.T) {
+			mockRepo.On("GetByHash", ctx, HashAPIKey(rawKey)).Return(nil, apperrors.ErrAPIKeyNotFound).Once()
+
+			key, err := service.Authenticate(ctx, rawKey)
+
+			assert.Nil(t, key)
+			assert.ErrorIs(t, err, apperrors.ErrAPIKeyInvalid)
+		})
+
+		t.Run("Expired Key", func(t *testing.T) {
+			expiresAt := now.Add(-time.Minute)
+			stored := &models.APIKey{ID: "key-1", Scopes: []models.APIKeyScope{models.ScopeRead}, ExpiresAt: &expiresAt}
+			mockRepo.On("GetByHash", ctx, HashAPIKey(rawKey)).Return(stored, nil).Once()
+
+			key, err := service.Authenticate(ctx, rawKey)
+
+			assert.Nil(t, key)
+			assert.ErrorIs(t, err, apperrors.ErrAPIKeyExpired)
+		})
+	})
+}
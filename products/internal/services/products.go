@@ -2,17 +2,40 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"contracts/productevent"
+	"fmt"
+	"maps"
+	"products/internal/auth"
 	"products/internal/metrics"
 	"products/internal/models"
-	"time"
+	"products/internal/outbox"
+	"products/internal/requestid"
+	"products/internal/tenant"
 
 	"go.uber.org/zap"
 )
 
-type MessageBroker interface {
-	Send(ctx context.Context, topic string, message, key []byte) error
-	Close() error
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, msg *models.OutboxMessage) error
+}
+
+type EventSerializer interface {
+	Serialize(event *productevent.Event) ([]byte, error)
+	ContentType() string
+}
+
+// WebhookEnqueuer queues product events for delivery to webhook subscriptions.
+type WebhookEnqueuer interface {
+	Enqueue(ctx context.Context, event *productevent.Event) error
+}
+
+// EventPublisher fans committed product events out to in-process subscribers.
+type EventPublisher interface {
+	Publish(event *productevent.Event)
 }
 
 type ProductsRepository interface {
@@ -22,42 +45,78 @@ type ProductsRepository interface {
 	List(ctx context.Context, listDTO *models.ListProductsDTO) ([]models.Product, error)
 }
 
+// ProductsService writes product events to the outbox, and queues their webhook
+// deliveries, in the same transaction as the change itself. The outbox relay and
+// the webhook dispatcher send them. Once committed, events are also published
+// to this replica's stream clients.
 type ProductsService struct {
-	repo   ProductsRepository
-	broker MessageBroker
-	logger *zap.Logger
+	repo       ProductsRepository
+	outbox     OutboxRepository
+	webhooks   WebhookEnqueuer
+	events     EventPublisher
+	tx         Transactor
+	serializer EventSerializer
+	logger     *zap.Logger
 }
 
-func NewProductsService(repo ProductsRepository, broker MessageBroker, logger *zap.Logger) *ProductsService {
+// NewProductsService creates the service, webhooks and events may be nil to
+// disable webhook deliveries and event streaming.
+func NewProductsService(repo ProductsRepository, outbox OutboxRepository, webhooks WebhookEnqueuer, events EventPublisher, tx Transactor, serializer EventSerializer, logger *zap.Logger) *ProductsService {
 	return &ProductsService{
-		repo:   repo,
-		broker: broker,
-		logger: logger.Named("ProductsService"),
+		repo:       repo,
+		outbox:     outbox,
+		webhooks:   webhooks,
+		events:     events,
+		tx:         tx,
+		serializer: serializer,
+		logger:     logger.Named("ProductsService"),
 	}
 }
 
 func (p *ProductsService) Create(ctx context.Context, productDTO *models.CreateProductDTO) (*models.Product, error) {
-	product, err := p.repo.Create(ctx, productDTO)
+	var product *models.Product
+	var event *productevent.Event
+	err := p.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		product, err = p.repo.Create(ctx, productDTO)
+		if err != nil {
+			return err
+		}
+		event, err = p.enqueueProductEvent(ctx, product, productevent.Created)
+		return err
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	p.publish(event)
+
 	metrics.ProductsCreated.Inc()
-	p.trySendProductEvent(ctx, product, models.ProductCreated)
 
 	return product, nil
 }
 
 func (p *ProductsService) Delete(ctx context.Context, id string) (*models.Product, error) {
-	product, err := p.repo.Delete(ctx, id)
+	var product *models.Product
+	var event *productevent.Event
+	err := p.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		product, err = p.repo.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+		event, err = p.enqueueProductEvent(ctx, product, productevent.Deleted)
+		return err
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	p.publish(event)
+
 	metrics.ProductsDeleted.Inc()
-	p.trySendProductEvent(ctx, product, models.ProductDeleted)
 
 	return product, nil
 }
@@ -76,30 +135,67 @@ func (p *ProductsService) List(ctx context.Context, listDTO *models.ListProducts
 	return products, total, nil
 }
 
-func (p *ProductsService) trySendProductEvent(ctx context.Context, product *models.Product, eventType models.ProductEventType) {
-	event := &models.ProductEvent{
-		EventType: eventType,
-		Product:   product,
-		Timestamp: time.Now(),
+func (p *ProductsService) enqueueProductEvent(ctx context.Context, product *models.Product, eventType productevent.Type) (*productevent.Event, error) {
+	tenantID, _ := tenant.FromContext(ctx)
+	correlationID, _ := requestid.FromContext(ctx)
+	event := productevent.New(eventType, productevent.Product{
+		ID:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       productevent.Cents(product.Price),
+		CreatedAt:   product.CreatedAt,
+	}, productevent.Metadata{
+		TenantID:      tenantID,
+		CorrelationID: correlationID,
+		Actor:         auth.SubjectFromContext(ctx),
+	})
+
+	msg, err := p.serializer.Serialize(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize product event: %w", err)
 	}
 
-	msg, err := json.Marshal(event)
+	headers := outbox.ContextHeaders(ctx)
+	maps.Copy(headers, productevent.Headers(event, p.serializer.ContentType()))
+
+	err = p.outbox.Enqueue(ctx, &models.OutboxMessage{
+		TenantID:    tenantID,
+		AggregateID: product.ID,
+		EventType:   string(eventType),
+		Payload:     msg,
+		Headers:     headers,
+	})
 	if err != nil {
-		p.logger.Error(
-			"failed to marshal product event",
-			zap.Error(err),
-			zap.String("product_id", product.ID),
-			zap.String("event_type", string(eventType)))
-		return
+		return nil, err
+	}
+	if p.webhooks != nil {
+		if err := p.webhooks.Enqueue(ctx, event); err != nil {
+			return nil, err
+		}
 	}
 
-	err = p.broker.Send(ctx, "", msg, []byte(product.ID))
-	if err != nil {
-		p.logger.Error(
-			"failed to send product event",
-			zap.Error(err),
-			zap.String("product_id", product.ID),
-			zap.String("event_type", string(eventType)),
-		)
+	return event, nil
+}
+
+func (p *ProductsService) publish(event *productevent.Event) {
+	if p.events != nil {
+		p.events.Publish(event)
 	}
 }
+
+// HandleDeliveryReport keeps track of product events the broker failed to
+// deliver. The broker reports every message in both delivery modes.
+func (p *ProductsService) HandleDeliveryReport(report models.DeliveryReport) {
+	if report.Err == nil {
+		return
+	}
+
+	metrics.ProductEventsUndelivered.Inc()
+	p.logger.Error(
+		"product event was not delivered",
+		zap.Error(report.Err),
+		zap.String("product_id", string(report.Key)),
+		zap.String("topic", report.Topic),
+		zap.String("request_id", report.RequestID),
+	)
+}
@@ -2,11 +2,15 @@ package services
 
 import (
 	"context"
+	"contracts/productevent"
 	"errors"
+	"products/internal/metrics"
 	"products/internal/models"
+	"products/internal/serde"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
@@ -45,25 +49,40 @@ func (m *MockProductsRepository) Count(ctx context.Context) (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
-type MockMessageBroker struct {
+type MockOutboxRepository struct {
 	mock.Mock
 }
 
-func (m *MockMessageBroker) Send(ctx context.Context, topic string, message, key []byte) error {
-	args := m.Called(ctx, topic, message, key)
+func (m *MockOutboxRepository) Enqueue(ctx context.Context, msg *models.OutboxMessage) error {
+	args := m.Called(ctx, msg)
 	return args.Error(0)
 }
 
-func (m *MockMessageBroker) Close() error {
-	args := m.Called()
-	return args.Error(0)
+// inlineTransactor runs fn without a transaction, the repositories are mocked anyway.
+type inlineTransactor struct{}
+
+func (inlineTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func outboxMessageFor(product *models.Product, eventType productevent.Type) any {
+	return mock.MatchedBy(func(msg *models.OutboxMessage) bool {
+		event, err := productevent.DecodeJSON(msg.Payload)
+		if err != nil {
+			return false
+		}
+		return msg.AggregateID == product.ID && msg.EventType == string(eventType) &&
+			msg.Headers["event-type"] == eventType.CloudEventType() && msg.Headers["content-type"] == "application/cloudevents+json" &&
+			event.ID != "" && event.Type == eventType && event.Subject == product.ID &&
+			event.Product.ID == product.ID && event.Product.Price == productevent.Cents(product.Price)
+	})
 }
 
 func TestProductService(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockProductsRepository)
-	mockBroker := new(MockMessageBroker)
-	service := NewProductsService(mockRepo, mockBroker, zap.NewNop())
+	mockOutbox := new(MockOutboxRepository)
+	service := NewProductsService(mockRepo, mockOutbox, nil, nil, inlineTransactor{}, serde.JSONSerializer{}, zap.NewNop())
 
 	t.Run("CreateProduct", func(t *testing.T) {
 		product := &models.Product{
@@ -81,17 +100,31 @@ func TestProductService(t *testing.T) {
 		t.Run("Success", func(t *testing.T) {
 
 			mockRepo.On("Create", ctx, createDTO).Return(product, nil).Once()
-			mockBroker.On("Send", ctx, "", mock.Anything, []byte(product.ID)).Return(nil).Once()
+			mockOutbox.On("Enqueue", ctx, outboxMessageFor(product, productevent.Created)).Return(nil).Once()
 			actualProduct, err := service.Create(ctx, createDTO)
 
 			assert.NoError(t, err)
 			assert.Equal(t, product, actualProduct)
 
 			mockRepo.AssertExpectations(t)
-			mockBroker.AssertExpectations(t)
+			mockOutbox.AssertExpectations(t)
 
 		})
 
+		t.Run("Outbox Error", func(t *testing.T) {
+			outboxErr := errors.New("outbox error")
+			mockRepo.On("Create", ctx, createDTO).Return(product, nil).Once()
+			mockOutbox.On("Enqueue", ctx, mock.Anything).Return(outboxErr).Once()
+
+			actualProduct, err := service.Create(ctx, createDTO)
+
+			assert.Nil(t, actualProduct)
+			assert.Equal(t, outboxErr, err, "The product must not be created without its event")
+
+			mockRepo.AssertExpectations(t)
+			mockOutbox.AssertExpectations(t)
+		})
+
 		t.Run("Error", func(t *testing.T) {
 			repoErr := errors.New("repository error")
 			mockRepo.On("Create", ctx, createDTO).Return(nil, repoErr).Once()
@@ -102,7 +135,7 @@ func TestProductService(t *testing.T) {
 			assert.Equal(t, repoErr, err)
 
 			mockRepo.AssertExpectations(t)
-			mockBroker.AssertNotCalled(t, "Send")
+			mockOutbox.AssertNotCalled(t, "Enqueue")
 		})
 
 	})
@@ -121,14 +154,14 @@ func TestProductService(t *testing.T) {
 		t.Run("Success", func(t *testing.T) {
 
 			mockRepo.On("Delete", ctx, deleteDTO.ID).Return(product, nil).Once()
-			mockBroker.On("Send", ctx, "", mock.Anything, []byte(product.ID)).Return(nil).Once()
+			mockOutbox.On("Enqueue", ctx, outboxMessageFor(product, productevent.Deleted)).Return(nil).Once()
 			actualProduct, err := service.Delete(ctx, deleteDTO.ID)
 
 			assert.NoError(t, err)
 			assert.Equal(t, product, actualProduct)
 
 			mockRepo.AssertExpectations(t)
-			mockBroker.AssertExpectations(t)
+			mockOutbox.AssertExpectations(t)
 
 		})
 
@@ -142,7 +175,7 @@ func TestProductService(t *testing.T) {
 			assert.Equal(t, repoErr, err)
 
 			mockRepo.AssertExpectations(t)
-			mockBroker.AssertNotCalled(t, "Send")
+			mockOutbox.AssertNotCalled(t, "Enqueue")
 		})
 	})
 
@@ -205,8 +238,66 @@ func TestProductService(t *testing.T) {
 			assert.Equal(t, repoErr, err)
 
 			mockRepo.AssertExpectations(t)
-			mockBroker.AssertNotCalled(t, "Send")
+			mockOutbox.AssertNotCalled(t, "Enqueue")
 		})
 	})
 
 }
+
+func TestProductServiceHandleDeliveryReport(t *testing.T) {
+	service := NewProductsService(new(MockProductsRepository), new(MockOutboxRepository), nil, nil, inlineTransactor{}, serde.JSONSerializer{}, zap.NewNop())
+	before := testutil.ToFloat64(metrics.ProductEventsUndelivered)
+
+	service.HandleDeliveryReport(models.DeliveryReport{Topic: "product-events", Key: []byte("uuid-1")})
+	assert.Equal(t, before, testutil.ToFloat64(metrics.ProductEventsUndelivered), "Delivered events should not be counted")
+
+	service.HandleDeliveryReport(models.DeliveryReport{Topic: "product-events", Key: []byte("uuid-1"), Err: errors.New("message timed out")})
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.ProductEventsUndelivered))
+}
+
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(event *productevent.Event) {
+	m.Called(event)
+}
+
+func TestProductServicePublishesCommittedEvents(t *testing.T) {
+	ctx := context.Background()
+	product := &models.Product{ID: "uuid-1", Name: "Test Product", Price: 100}
+	createDTO := &models.CreateProductDTO{Name: product.Name, Price: product.Price}
+
+	t.Run("Committed", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		mockOutbox := new(MockOutboxRepository)
+		publisher := new(MockEventPublisher)
+		service := NewProductsService(mockRepo, mockOutbox, nil, publisher, inlineTransactor{}, serde.JSONSerializer{}, zap.NewNop())
+
+		mockRepo.On("Create", ctx, createDTO).Return(product, nil).Once()
+		mockOutbox.On("Enqueue", ctx, mock.Anything).Return(nil).Once()
+		publisher.On("Publish", mock.MatchedBy(func(event *productevent.Event) bool {
+			return event.Type == productevent.Created && event.Product.ID == product.ID
+		})).Once()
+
+		_, err := service.Create(ctx, createDTO)
+
+		assert.NoError(t, err)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("Rolled Back", func(t *testing.T) {
+		mockRepo := new(MockProductsRepository)
+		mockOutbox := new(MockOutboxRepository)
+		publisher := new(MockEventPublisher)
+		service := NewProductsService(mockRepo, mockOutbox, nil, publisher, inlineTransactor{}, serde.JSONSerializer{}, zap.NewNop())
+
+		mockRepo.On("Create", ctx, createDTO).Return(product, nil).Once()
+		mockOutbox.On("Enqueue", ctx, mock.Anything).Return(errors.New("outbox error")).Once()
+
+		_, err := service.Create(ctx, createDTO)
+
+		assert.Error(t, err)
+		publisher.AssertNotCalled(t, "Publish")
+	})
+}
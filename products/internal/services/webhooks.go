@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"contracts/productevent"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"products/internal/apperrors"
+	"products/internal/models"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	minWebhookSecretLen = 16
+	// maxWebhookDeliveries caps the delivery log returned per subscription.
+	maxWebhookDeliveries = 100
+)
+
+type WebhooksRepository interface {
+	Create(ctx context.Context, sub *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	Get(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	List(ctx context.Context) ([]models.WebhookSubscription, error)
+	ListActive(ctx context.Context, eventType string) ([]models.WebhookSubscription, error)
+	Update(ctx context.Context, id string, update *models.UpdateWebhookDTO) (*models.WebhookSubscription, error)
+	Delete(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	Deliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error)
+}
+
+// WebhooksService manages the webhook subscriptions of the tenant from ctx and
+// queues product events for delivery to them, see webhooks.Dispatcher.
+type WebhooksService struct {
+	repo   WebhooksRepository
+	logger *zap.Logger
+}
+
+func NewWebhooksService(repo WebhooksRepository, logger *zap.Logger) *WebhooksService {
+	return &WebhooksService{
+		repo:   repo,
+		logger: logger.Named("WebhooksService"),
+	}
+}
+
+// Create stores a new subscription and returns it together with its signing
+// secret, which is only available here.
+func (s *WebhooksService) Create(ctx context.Context, createDTO *models.CreateWebhookDTO) (*models.WebhookSubscription, string, error) {
+	if err := validateWebhookURL(createDTO.URL); err != nil {
+		return nil, "", err
+	}
+	if err := validateEventTypes(createDTO.EventTypes); err != nil {
+		return nil, "", err
+	}
+
+	secret := createDTO.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, "", err
+		}
+	} else if len(secret) < minWebhookSecretLen {
+		return nil, "", fmt.Errorf("%w: secret must have at least %d characters", apperrors.ErrWebhookInvalid, minWebhookSecretLen)
+	}
+
+	eventTypes := createDTO.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	sub, err := s.repo.Create(ctx, &models.WebhookSubscription{
+		URL:        createDTO.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return sub, secret, nil
+}
+
+func (s *WebhooksService) Get(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *WebhooksService) List(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.repo.List(ctx)
+}
+
+// Update changes the URL, event types or active flag. Activating a subscription
+// that was disabled after failures gives it a fresh start.
+func (s *WebhooksService) Update(ctx context.Context, id string, updateDTO *models.UpdateWebhookDTO) (*models.WebhookSubscription, error) {
+	if updateDTO.URL != nil {
+		if err := validateWebhookURL(*updateDTO.URL); err != nil {
+			return nil, err
+		}
+	}
+	if updateDTO.EventTypes != nil {
+		if err := validateEventTypes(*updateDTO.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Update(ctx, id, updateDTO)
+}
+
+func (s *WebhooksService) Delete(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	return s.repo.Delete(ctx, id)
+}
+
+// Deliveries returns the latest deliveries of a subscription, newest first.
+func (s *WebhooksService) Deliveries(ctx context.Context, id string, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > maxWebhookDeliveries {
+		limit = maxWebhookDeliveries
+	}
+
+	return s.repo.Deliveries(ctx, id, limit)
+}
+
+// Enqueue queues event for every active subscription of the tenant from ctx that
+// receives its type. Partners always get the JSON CloudEvent, independent of the
+// format events are published to the message broker in.
+func (s *WebhooksService) Enqueue(ctx context.Context, event *productevent.Event) error {
+	subs, err := s.repo.ListActive(ctx, string(event.Type))
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	payload, err := productevent.EncodeJSON(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(subs))
+	for _, sub := range subs {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      string(event.Type),
+			Payload:        payload,
+		})
+	}
+
+	return s.repo.CreateDeliveries(ctx, deliveries)
+}
+
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https url", apperrors.ErrWebhookInvalid)
+	}
+	return nil
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !productevent.Type(eventType).Valid() {
+			return fmt.Errorf("%w: unknown event type %q", apperrors.ErrWebhookInvalid, eventType)
+		}
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(eventTypes)))) != len(eventTypes) {
+		return fmt.Errorf("%w: duplicate event types", apperrors.ErrWebhookInvalid)
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"contracts/productevent"
+	"products/internal/apperrors"
+	"products/internal/models"
+	"products/internal/serde"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockWebhooksRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhooksRepository) Create(ctx context.Context, sub *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhooksRepository) Get(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhooksRepository) List(ctx context.Context) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhooksRepository) ListActive(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx, eventType)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhooksRepository) Update(ctx context.Context, id string, update *models.UpdateWebhookDTO) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhooksRepository) Delete(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhooksRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhooksRepository) Deliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhooksServiceCreate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Generates Secret", func(t *testing.T) {
+		repo := new(MockWebhooksRepository)
+		service := NewWebhooksService(repo, zap.NewNop())
+
+		repo.On("Create", ctx, mock.MatchedBy(func(sub *models.WebhookSubscription) bool {
+			return sub.URL == "https://partner.example.com/hooks" && len(sub.EventTypes) == 0 && strings.HasPrefix(sub.Secret, webhookSecretPrefix)
+		})).Return(&models.WebhookSubscription{ID: "sub-1"}, nil).Once()
+
+		sub, secret, err := service.Create(ctx, &models.CreateWebhookDTO{URL: "https://partner.example.com/hooks"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "sub-1", sub.ID)
+		assert.True(t, strings.HasPrefix(secret, webhookSecretPrefix))
+		repo.AssertExpectations(t)
+	})
+
+	type testCase struct {
+		name      string
+		createDTO models.CreateWebhookDTO
+	}
+
+	cases := []testCase{
+		{name: "Relative URL", createDTO: models.CreateWebhookDTO{URL: "/hooks"}},
+		{name: "Unsupported Scheme", createDTO: models.CreateWebhookDTO{URL: "ftp://partner.example.com/hooks"}},
+		{name: "Unknown Event Type", createDTO: models.CreateWebhookDTO{URL: "https://partner.example.com", EventTypes: []string{"product_renamed"}}},
+		{name: "Duplicate Event Type", createDTO: models.CreateWebhookDTO{URL: "https://partner.example.com", EventTypes: []string{"product_created", "product_created"}}},
+		{name: "Short Secret", createDTO: models.CreateWebhookDTO{URL: "https://partner.example.com", Secret: "short"}},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			repo := new(MockWebhooksRepository)
+			service := NewWebhooksService(repo, zap.NewNop())
+
+			_, _, err := service.Create(ctx, &tCase.createDTO)
+
+			assert.ErrorIs(t, err, apperrors.ErrWebhookInvalid)
+			repo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestWebhooksServiceEnqueue(t *testing.T) {
+	ctx := context.Background()
+	event := productevent.New(productevent.Created, productevent.Product{ID: "uuid-1", Name: "Test Product", Price: 12345}, productevent.Metadata{TenantID: "default"})
+
+	t.Run("Queues Delivery Per Subscription", func(t *testing.T) {
+		repo := new(MockWebhooksRepository)
+		service := NewWebhooksService(repo, zap.NewNop())
+
+		repo.On("ListActive", ctx, "product_created").Return([]models.WebhookSubscription{{ID: "sub-1"}, {ID: "sub-2"}}, nil).Once()
+		repo.On("CreateDeliveries", ctx, mock.MatchedBy(func(deliveries []models.WebhookDelivery) bool {
+			if len(deliveries) != 2 || deliveries[0].SubscriptionID != "sub-1" || deliveries[1].SubscriptionID != "sub-2" {
+				return false
+			}
+			decoded, err := productevent.DecodeJSON(deliveries[0].Payload)
+			return err == nil && decoded.ID == event.ID && deliveries[0].EventID == event.ID && deliveries[0].EventType == "product_created"
+		})).Return(nil).Once()
+
+		require.NoError(t, service.Enqueue(ctx, event))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("No Subscriptions", func(t *testing.T) {
+		repo := new(MockWebhooksRepository)
+		service := NewWebhooksService(repo, zap.NewNop())
+
+		repo.On("ListActive", ctx, "product_created").Return([]models.WebhookSubscription{}, nil).Once()
+
+		require.NoError(t, service.Enqueue(ctx, event))
+		repo.AssertNotCalled(t, "CreateDeliveries")
+	})
+}
+
+func TestProductServiceEnqueuesWebhooks(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockProductsRepository)
+	mockOutbox := new(MockOutboxRepository)
+	webhooksRepo := new(MockWebhooksRepository)
+	service := NewProductsService(mockRepo, mockOutbox, NewWebhooksService(webhooksRepo, zap.NewNop()), nil, inlineTransactor{}, serde.JSONSerializer{}, zap.NewNop())
+
+	product := &models.Product{ID: "uuid-1", Name: "Test Product", Price: 100}
+	createDTO := &models.CreateProductDTO{Name: product.Name, Price: product.Price}
+	mockRepo.On("Create", ctx, createDTO).Return(product, nil).Once()
+	mockOutbox.On("Enqueue", ctx, outboxMessageFor(product, productevent.Created)).Return(nil).Once()
+	webhooksRepo.On("ListActive", ctx, "product_created").Return([]models.WebhookSubscription{{ID: "sub-1"}}, nil).Once()
+	webhooksRepo.On("CreateDeliveries", ctx, mock.MatchedBy(func(deliveries []models.WebhookDelivery) bool {
+		return len(deliveries) == 1 && deliveries[0].SubscriptionID == "sub-1"
+	})).Return(nil).Once()
+
+	_, err := service.Create(ctx, createDTO)
+
+	require.NoError(t, err)
+	mockOutbox.AssertExpectations(t)
+	webhooksRepo.AssertExpectations(t)
+}
@@ -0,0 +1,252 @@
+// Package stream fans product events out to clients connected to this process,
+// e.g. Server-Sent Events streams.
+package stream
+
+import (
+	"cmp"
+	"contracts/productevent"
+	"errors"
+	"fmt"
+	"products/internal/metrics"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrClosed         = errors.New("stream hub is closed")
+	ErrTooManyClients = errors.New("too many stream clients")
+)
+
+// Event is a published product event with its position in the hub.
+type Event struct {
+	// ID is "<boot id>-<sequence>", unique across restarts of the hub.
+	ID        string
+	Seq       uint64
+	Type      string
+	TenantID  string
+	ProductID string
+	Price     productevent.Cents
+	// Data is the JSON CloudEvent.
+	Data []byte
+}
+
+// Filter selects the events of a subscription. Empty lists and nil prices match
+// everything, price bounds are inclusive.
+type Filter struct {
+	TenantID   string
+	Types      []string
+	ProductIDs []string
+	MinPrice   *productevent.Cents
+	MaxPrice   *productevent.Cents
+}
+
+func (f Filter) Match(e *Event) bool {
+	if e.TenantID != f.TenantID {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if len(f.ProductIDs) > 0 && !slices.Contains(f.ProductIDs, e.ProductID) {
+		return false
+	}
+	if f.MinPrice != nil && e.Price < *f.MinPrice {
+		return false
+	}
+	if f.MaxPrice != nil && e.Price > *f.MaxPrice {
+		return false
+	}
+	return true
+}
+
+type Config struct {
+	// HistorySize is how many recent events are kept for resuming subscriptions.
+	HistorySize int
+	// BufferSize is how many events may queue up for a subscriber before it is
+	// dropped as too slow.
+	BufferSize int
+	// MaxClients limits concurrent subscriptions, 0 means no limit.
+	MaxClients int
+}
+
+// Subscription receives the matching events published after it was created,
+// preceded by the ones it resumed from the history.
+type Subscription struct {
+	events chan *Event
+	filter Filter
+	// resync is set when events the subscriber asked for are no longer in the history.
+	resync bool
+	lagged bool
+}
+
+// Events is closed when the subscriber lagged behind or the hub was closed.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Resync reports that events were missed since the requested last event id,
+// so the client should reload its state.
+func (s *Subscription) Resync() bool {
+	return s.resync
+}
+
+// Lagged reports that the subscription was dropped because its buffer was full.
+// Only valid after Events is closed.
+func (s *Subscription) Lagged() bool {
+	return s.lagged
+}
+
+// Hub keeps recent events in memory and sends every published event to the
+// matching subscriptions. Publish never blocks: a subscriber whose buffer is
+// full is dropped and can resume with the last event id it received.
+type Hub struct {
+	cfg    Config
+	bootID string
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	seq     uint64
+	history []*Event
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+func NewHub(cfg Config, logger *zap.Logger) *Hub {
+	return &Hub{
+		cfg:    cfg,
+		bootID: strconv.FormatInt(time.Now().UnixNano(), 36),
+		logger: logger.Named("StreamHub"),
+		subs:   map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends event to the matching subscriptions, it is called after the
+// transaction that produced the event committed.
+func (h *Hub) Publish(event *productevent.Event) {
+	data, err := productevent.EncodeJSON(event)
+	if err != nil {
+		h.logger.Error("Failed to encode stream event", zap.Error(err), zap.String("event_id", event.ID))
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.seq++
+	e := &Event{
+		ID:        h.eventID(h.seq),
+		Seq:       h.seq,
+		Type:      string(event.Type),
+		TenantID:  event.TenantID,
+		ProductID: event.Product.ID,
+		Price:     event.Product.Price,
+		Data:      data,
+	}
+	h.history = append(h.history, e)
+	if len(h.history) > h.cfg.HistorySize {
+		h.history = slices.Delete(h.history, 0, len(h.history)-h.cfg.HistorySize)
+	}
+
+	for sub := range h.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			sub.lagged = true
+			h.remove(sub)
+			metrics.StreamClientsDropped.Inc()
+		}
+	}
+}
+
+// Subscribe creates a subscription. A non-empty lastEventID resumes after that
+// event from the history.
+func (h *Hub) Subscribe(filter Filter, lastEventID string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	if h.cfg.MaxClients > 0 && len(h.subs) >= h.cfg.MaxClients {
+		return nil, ErrTooManyClients
+	}
+
+	var replay []*Event
+	var resync bool
+	if lastEventID != "" {
+		replay, resync = h.since(lastEventID)
+		replay = slices.DeleteFunc(replay, func(e *Event) bool { return !filter.Match(e) })
+	}
+
+	sub := &Subscription{
+		events: make(chan *Event, h.cfg.BufferSize+len(replay)),
+		filter: filter,
+		resync: resync,
+	}
+	for _, e := range replay {
+		sub.events <- e
+	}
+	h.subs[sub] = struct{}{}
+	metrics.StreamClients.Inc()
+
+	return sub, nil
+}
+
+// since returns the history after lastEventID and whether events were missed
+// in between: the id is older than the history or isn't one of this boot's. An
+// id that isn't this boot's gets no replay, so made up ids can't be used to
+// fetch the whole history.
+func (h *Hub) since(lastEventID string) ([]*Event, bool) {
+	bootID, seqStr, ok := strings.Cut(lastEventID, "-")
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if !ok || err != nil || bootID != h.bootID || seq > h.seq {
+		return nil, true
+	}
+
+	i, _ := slices.BinarySearchFunc(h.history, seq+1, func(e *Event, target uint64) int {
+		return cmp.Compare(e.Seq, target)
+	})
+	missed := seq < h.seq && (len(h.history) == 0 || h.history[0].Seq > seq+1)
+	return slices.Clone(h.history[i:]), missed
+}
+
+// Unsubscribe removes sub, it is safe to call after the hub dropped it.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.events)
+	metrics.StreamClients.Dec()
+}
+
+// Close ends every subscription and rejects new ones, so open streams don't
+// hold up a graceful shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.remove(sub)
+	}
+}
+
+func (h *Hub) eventID(seq uint64) string {
+	return fmt.Sprintf("%s-%d", h.bootID, seq)
+}
@@ -0,0 +1,201 @@
+package stream
+
+import (
+	"contracts/productevent"
+	"products/internal/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newEvent(eventType productevent.Type, tenantID, productID string) *productevent.Event {
+	return newPricedEvent(eventType, tenantID, productID, 100)
+}
+
+func newPricedEvent(eventType productevent.Type, tenantID, productID string, price productevent.Cents) *productevent.Event {
+	return productevent.New(eventType, productevent.Product{ID: productID, Name: "Test Product", Price: price}, productevent.Metadata{TenantID: tenantID})
+}
+
+func cents(c productevent.Cents) *productevent.Cents {
+	return &c
+}
+
+// received drains the buffered events of sub.
+func received(sub *Subscription) []*Event {
+	var events []*Event
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func productIDs(events []*Event) []string {
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ProductID)
+	}
+	return ids
+}
+
+func TestHubFilters(t *testing.T) {
+	hub := NewHub(Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+
+	type testCase struct {
+		name     string
+		filter   Filter
+		expected []string
+	}
+
+	cases := []testCase{
+		{name: "Tenant", filter: Filter{TenantID: "acme"}, expected: []string{"p1", "p2", "p3"}},
+		{name: "Other Tenant", filter: Filter{TenantID: "globex"}, expected: []string{"p4"}},
+		{name: "Types", filter: Filter{TenantID: "acme", Types: []string{"product_deleted"}}, expected: []string{"p2"}},
+		{name: "Product IDs", filter: Filter{TenantID: "acme", ProductIDs: []string{"p1", "p3"}}, expected: []string{"p1", "p3"}},
+		{name: "Min Price", filter: Filter{TenantID: "acme", MinPrice: cents(5000)}, expected: []string{"p2", "p3"}},
+		{name: "Price Range", filter: Filter{TenantID: "acme", MinPrice: cents(5000), MaxPrice: cents(10000)}, expected: []string{"p2"}},
+	}
+
+	subs := make([]*Subscription, len(cases))
+	for i, tCase := range cases {
+		sub, err := hub.Subscribe(tCase.filter, "")
+		require.NoError(t, err)
+		subs[i] = sub
+	}
+
+	hub.Publish(newPricedEvent(productevent.Created, "acme", "p1", 100))
+	hub.Publish(newPricedEvent(productevent.Deleted, "acme", "p2", 10000))
+	hub.Publish(newPricedEvent(productevent.Created, "acme", "p3", 25000))
+	hub.Publish(newPricedEvent(productevent.Created, "globex", "p4", 100))
+
+	for i, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			assert.Equal(t, tCase.expected, productIDs(received(subs[i])))
+		})
+	}
+}
+
+func TestHubEventData(t *testing.T) {
+	hub := NewHub(Config{HistorySize: 10, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+
+	sub, err := hub.Subscribe(Filter{TenantID: "acme"}, "")
+	require.NoError(t, err)
+
+	event := newEvent(productevent.Created, "acme", "p1")
+	hub.Publish(event)
+
+	events := received(sub)
+	require.Len(t, events, 1)
+	assert.Equal(t, "product_created", events[0].Type)
+	assert.Equal(t, hub.eventID(1), events[0].ID)
+
+	decoded, err := productevent.DecodeJSON(events[0].Data)
+	require.NoError(t, err)
+	assert.Equal(t, event.ID, decoded.ID)
+}
+
+func TestHubResume(t *testing.T) {
+	hub := NewHub(Config{HistorySize: 3, BufferSize: 10}, zap.NewNop())
+	defer hub.Close()
+
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5"} {
+		hub.Publish(newEvent(productevent.Created, "acme", id))
+	}
+
+	type testCase struct {
+		name        string
+		lastEventID string
+		expected    []string
+		resync      bool
+	}
+
+	cases := []testCase{
+		{name: "Within History", lastEventID: hub.eventID(3), expected: []string{"p4", "p5"}},
+		{name: "Up To Date", lastEventID: hub.eventID(5), expected: []string{}},
+		{name: "Right Before History", lastEventID: hub.eventID(2), expected: []string{"p3", "p4", "p5"}},
+		{name: "Older Than History", lastEventID: hub.eventID(1), expected: []string{"p3", "p4", "p5"}, resync: true},
+		{name: "Earlier Boot", lastEventID: "previous-4", expected: []string{}, resync: true},
+		{name: "Malformed", lastEventID: "garbage", expected: []string{}, resync: true},
+		{name: "Future Sequence", lastEventID: hub.eventID(9), expected: []string{}, resync: true},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			sub, err := hub.Subscribe(Filter{TenantID: "acme"}, tCase.lastEventID)
+			require.NoError(t, err)
+			defer hub.Unsubscribe(sub)
+
+			assert.Equal(t, tCase.expected, productIDs(received(sub)))
+			assert.Equal(t, tCase.resync, sub.Resync())
+		})
+	}
+
+	t.Run("Replay Respects Filter", func(t *testing.T) {
+		sub, err := hub.Subscribe(Filter{TenantID: "globex"}, hub.eventID(3))
+		require.NoError(t, err)
+		defer hub.Unsubscribe(sub)
+
+		assert.Empty(t, received(sub))
+	})
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(Config{HistorySize: 10, BufferSize: 2}, zap.NewNop())
+	defer hub.Close()
+
+	slow, err := hub.Subscribe(Filter{TenantID: "acme"}, "")
+	require.NoError(t, err)
+	fast, err := hub.Subscribe(Filter{TenantID: "acme"}, "")
+	require.NoError(t, err)
+
+	droppedBefore := testutil.ToFloat64(metrics.StreamClientsDropped)
+
+	var fastReceived []*Event
+	for _, id := range []string{"p1", "p2", "p3"} {
+		hub.Publish(newEvent(productevent.Created, "acme", id))
+		fastReceived = append(fastReceived, received(fast)...)
+	}
+
+	assert.Equal(t, []string{"p1", "p2", "p3"}, productIDs(fastReceived), "A slow subscriber must not hold up the others")
+	assert.Equal(t, []string{"p1", "p2"}, productIDs(received(slow)))
+	_, open := <-slow.Events()
+	assert.False(t, open)
+	assert.True(t, slow.Lagged())
+	assert.Equal(t, droppedBefore+1, testutil.ToFloat64(metrics.StreamClientsDropped))
+
+	resumed, err := hub.Subscribe(Filter{TenantID: "acme"}, hub.eventID(2))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p3"}, productIDs(received(resumed)), "A dropped subscriber resumes from its last event")
+	assert.False(t, resumed.Resync())
+}
+
+func TestHubLimitsAndClose(t *testing.T) {
+	hub := NewHub(Config{HistorySize: 10, BufferSize: 2, MaxClients: 1}, zap.NewNop())
+
+	sub, err := hub.Subscribe(Filter{TenantID: "acme"}, "")
+	require.NoError(t, err)
+
+	_, err = hub.Subscribe(Filter{TenantID: "acme"}, "")
+	assert.ErrorIs(t, err, ErrTooManyClients)
+
+	hub.Close()
+
+	_, open := <-sub.Events()
+	assert.False(t, open)
+	assert.False(t, sub.Lagged())
+	hub.Unsubscribe(sub)
+
+	_, err = hub.Subscribe(Filter{TenantID: "acme"}, "")
+	assert.ErrorIs(t, err, ErrClosed)
+}
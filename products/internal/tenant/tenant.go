@@ -0,0 +1,29 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Default is used when neither the caller nor the request names a tenant,
+// so single brand deployments keep working without any configuration.
+const Default = "default"
+
+const Header = "X-Tenant-ID"
+
+var idPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+type tenantKey struct{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok && id != ""
+}
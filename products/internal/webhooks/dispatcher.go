@@ -0,0 +1,218 @@
+// Package webhooks sends product events to the HTTP endpoints of webhook
+// subscriptions, signed with the subscription's secret.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"contracts/productevent"
+	"fmt"
+	"io"
+	"net/http"
+	"products/internal/metrics"
+	"products/internal/models"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxResponseBody is how much of a response body is read and kept as the error.
+const maxResponseBody = 1 << 10
+
+type Repository interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, responseStatus int) error
+	MarkFailed(ctx context.Context, id int64, attempt models.WebhookAttempt, disableAfter int) (bool, error)
+	DeleteFinished(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Timeout bounds a single delivery request.
+	Timeout time.Duration
+	// MaxAttempts is how often a delivery is tried before it fails for good.
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	// DisableAfter is the number of consecutive failed attempts after which a
+	// subscription is disabled.
+	DisableAfter int
+	// Retention is how long delivered and failed deliveries are kept for the delivery log.
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+// Dispatcher sends due webhook deliveries and records their outcome. Deliveries
+// are claimed with a lease, so every replica can run a dispatcher. Delivery is
+// at least once, receivers should deduplicate by the event id header.
+type Dispatcher struct {
+	repo   Repository
+	client *http.Client
+	cfg    Config
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewDispatcher(repo Repository, cfg Config, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// Redirects aren't followed, the subscription should point to the final URL.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:    cfg,
+		logger: logger.Named("WebhookDispatcher"),
+		now:    time.Now,
+	}
+}
+
+// Run sends due deliveries every poll interval and removes old finished ones
+// every cleanup interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	poll := time.NewTicker(d.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(d.cfg.CleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		d.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		case <-cleanup.C:
+			d.cleanup(ctx)
+		}
+	}
+}
+
+// drain sends batches until there is nothing due left.
+func (d *Dispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		claimed, err := d.dispatchBatch(ctx)
+		if err != nil {
+			d.logger.Error("Failed to dispatch webhook deliveries:", zap.Error(err))
+			return
+		}
+		if claimed < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// dispatchBatch sends one batch of due deliveries concurrently and returns how many were claimed.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	// The lease outlasts the batch, whose requests run concurrently.
+	deliveries, err := d.repo.Claim(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.dispatch(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, delivery models.WebhookDelivery) {
+	logger := d.logger.With(
+		zap.Int64("delivery_id", delivery.ID),
+		zap.String("subscription_id", delivery.SubscriptionID),
+		zap.String("event_id", delivery.EventID),
+	)
+
+	status, err := d.send(ctx, delivery)
+	if err == nil {
+		metrics.WebhookDeliveryAttempts.WithLabelValues("delivered").Inc()
+		if err := d.repo.MarkDelivered(ctx, delivery.ID, status); err != nil {
+			logger.Error("Failed to mark webhook delivered:", zap.Error(err))
+		}
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	attempt := models.WebhookAttempt{ResponseStatus: status, Error: err.Error()}
+	if attempts < d.cfg.MaxAttempts {
+		next := d.now().Add(d.backoff(attempts))
+		attempt.NextAttemptAt = &next
+		metrics.WebhookDeliveryAttempts.WithLabelValues("retry").Inc()
+		logger.Warn("Failed to deliver webhook:", zap.Error(err), zap.Int("attempts", attempts), zap.Time("retry_at", next))
+	} else {
+		metrics.WebhookDeliveryAttempts.WithLabelValues("failed").Inc()
+		logger.Error("Giving up on webhook delivery:", zap.Error(err), zap.Int("attempts", attempts))
+	}
+
+	disabled, err := d.repo.MarkFailed(ctx, delivery.ID, attempt, d.cfg.DisableAfter)
+	if err != nil {
+		logger.Error("Failed to mark webhook failed:", zap.Error(err))
+		return
+	}
+	if disabled {
+		metrics.WebhookSubscriptionsDisabled.Inc()
+		logger.Warn("Disabled webhook subscription after consecutive failures", zap.Int("disable_after", d.cfg.DisableAfter))
+	}
+}
+
+// send posts the delivery and returns the response status, any status but 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", productevent.JSONContentType)
+	req.Header.Set("User-Agent", "products-webhooks/1")
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, d.now(), delivery.Payload))
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(EventIDHeader, delivery.EventID)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	metrics.WebhookDeliveryDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the delay with every attempt, starting at MinBackoff and capped at MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.MinBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
+
+func (d *Dispatcher) cleanup(ctx context.Context) {
+	deleted, err := d.repo.DeleteFinished(ctx, d.now().Add(-d.cfg.Retention))
+	if err != nil {
+		d.logger.Error("Failed to clean up webhook deliveries:", zap.Error(err))
+		return
+	}
+
+	metrics.WebhookDeliveriesDeleted.Add(float64(deleted))
+	if deleted > 0 {
+		d.logger.Info("Cleaned up webhook deliveries", zap.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,220 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"products/internal/metrics"
+	"products/internal/models"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) MarkDelivered(ctx context.Context, id int64, responseStatus int) error {
+	args := m.Called(ctx, id, responseStatus)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkFailed(ctx context.Context, id int64, attempt models.WebhookAttempt, disableAfter int) (bool, error) {
+	args := m.Called(ctx, id, attempt, disableAfter)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteFinished(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// receiver records the requests it gets and answers with the status for the delivery header.
+type receiver struct {
+	mu       sync.Mutex
+	requests map[string]*http.Request
+	bodies   map[string][]byte
+	statuses map[string]int
+}
+
+func newReceiver(t *testing.T, statuses map[string]int) (*httptest.Server, *receiver) {
+	r := &receiver{requests: map[string]*http.Request{}, bodies: map[string][]byte{}, statuses: statuses}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		id := req.Header.Get(DeliveryHeader)
+
+		r.mu.Lock()
+		r.requests[id] = req
+		r.bodies[id] = body
+		status := r.statuses[id]
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("receiver says " + http.StatusText(status)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, r
+}
+
+func newTestDispatcher(repo Repository) *Dispatcher {
+	dispatcher := NewDispatcher(repo, Config{
+		BatchSize:    10,
+		Timeout:      time.Second,
+		MaxAttempts:  3,
+		MinBackoff:   time.Second,
+		MaxBackoff:   time.Minute,
+		DisableAfter: 5,
+		Retention:    24 * time.Hour,
+	}, zap.NewNop())
+	now := time.Now().Truncate(time.Second)
+	dispatcher.now = func() time.Time { return now }
+	return dispatcher
+}
+
+func TestDispatcherDispatchBatch(t *testing.T) {
+	ctx := context.Background()
+	srv, r := newReceiver(t, map[string]int{"1": http.StatusNoContent, "2": http.StatusInternalServerError, "3": http.StatusGone})
+
+	payload := []byte(`{"type":"products.product_created"}`)
+	deliveries := []models.WebhookDelivery{
+		{ID: 1, SubscriptionID: "sub-1", EventID: "event-1", EventType: "product_created", Payload: payload, URL: srv.URL, Secret: "secret-1"},
+		{ID: 2, SubscriptionID: "sub-2", EventID: "event-1", EventType: "product_created", Payload: payload, Attempts: 1, URL: srv.URL, Secret: "secret-2"},
+		{ID: 3, SubscriptionID: "sub-3", EventID: "event-1", EventType: "product_created", Payload: payload, Attempts: 2, URL: srv.URL, Secret: "secret-3"},
+	}
+
+	repo := new(MockRepository)
+	dispatcher := newTestDispatcher(repo)
+	retryAt := dispatcher.now().Add(2 * time.Second)
+
+	repo.On("Claim", mock.Anything, 10, 2*time.Second).Return(deliveries, nil).Once()
+	repo.On("MarkDelivered", mock.Anything, int64(1), http.StatusNoContent).Return(nil).Once()
+	repo.On("MarkFailed", mock.Anything, int64(2), mock.MatchedBy(func(attempt models.WebhookAttempt) bool {
+		return attempt.ResponseStatus == http.StatusInternalServerError &&
+			strings.Contains(attempt.Error, "receiver says Internal Server Error") &&
+			attempt.NextAttemptAt != nil && attempt.NextAttemptAt.Equal(retryAt)
+	}), 5).Return(false, nil).Once()
+	repo.On("MarkFailed", mock.Anything, int64(3), mock.MatchedBy(func(attempt models.WebhookAttempt) bool {
+		return attempt.ResponseStatus == http.StatusGone && attempt.NextAttemptAt == nil
+	}), 5).Return(true, nil).Once()
+
+	disabledBefore := testutil.ToFloat64(metrics.WebhookSubscriptionsDisabled)
+
+	claimed, err := dispatcher.dispatchBatch(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, claimed)
+	repo.AssertExpectations(t)
+	assert.Equal(t, disabledBefore+1, testutil.ToFloat64(metrics.WebhookSubscriptionsDisabled))
+
+	req := r.requests["1"]
+	require.NotNil(t, req)
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "application/cloudevents+json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "product_created", req.Header.Get(EventTypeHeader))
+	assert.Equal(t, "event-1", req.Header.Get(EventIDHeader))
+	assert.Equal(t, payload, r.bodies["1"])
+	assert.NoError(t, Verify("secret-1", req.Header.Get(SignatureHeader), r.bodies["1"], time.Minute, time.Now()))
+	assert.ErrorIs(t, Verify("secret-2", req.Header.Get(SignatureHeader), r.bodies["1"], time.Minute, time.Now()), ErrInvalidSignature,
+		"Every subscription signs with its own secret")
+}
+
+func TestDispatcherUnreachableEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	repo := new(MockRepository)
+	dispatcher := newTestDispatcher(repo)
+
+	repo.On("Claim", mock.Anything, 10, 2*time.Second).Return([]models.WebhookDelivery{{ID: 1, SubscriptionID: "sub-1", URL: url, Secret: "secret"}}, nil).Once()
+	repo.On("MarkFailed", mock.Anything, int64(1), mock.MatchedBy(func(attempt models.WebhookAttempt) bool {
+		return attempt.ResponseStatus == 0 && attempt.Error != "" && attempt.NextAttemptAt != nil
+	}), 5).Return(false, nil).Once()
+
+	_, err := dispatcher.dispatchBatch(context.Background())
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	dispatcher := newTestDispatcher(new(MockRepository))
+
+	type testCase struct {
+		attempts int
+		expected time.Duration
+	}
+
+	cases := []testCase{
+		{attempts: 1, expected: time.Second},
+		{attempts: 2, expected: 2 * time.Second},
+		{attempts: 5, expected: 16 * time.Second},
+		{attempts: 100, expected: time.Minute},
+	}
+
+	for _, tCase := range cases {
+		assert.Equal(t, tCase.expected, dispatcher.backoff(tCase.attempts), "attempts %d", tCase.attempts)
+	}
+}
+
+func TestDispatcherCleanup(t *testing.T) {
+	repo := new(MockRepository)
+	dispatcher := newTestDispatcher(repo)
+
+	repo.On("DeleteFinished", mock.Anything, dispatcher.now().Add(-24*time.Hour)).Return(int64(3), nil).Once()
+
+	dispatcher.cleanup(context.Background())
+
+	repo.AssertExpectations(t)
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":"event-1"}`)
+	header := Sign("secret", now, body)
+
+	type testCase struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		valid  bool
+	}
+
+	cases := []testCase{
+		{name: "Valid", secret: "secret", header: header, body: body, now: now, valid: true},
+		{name: "Wrong Secret", secret: "other", header: header, body: body, now: now},
+		{name: "Tampered Body", secret: "secret", header: header, body: []byte(`{"id":"event-2"}`), now: now},
+		{name: "Too Old", secret: "secret", header: header, body: body, now: now.Add(10 * time.Minute)},
+		{name: "Malformed", secret: "secret", header: "v1=abc", body: body, now: now},
+	}
+
+	for _, tCase := range cases {
+		t.Run(tCase.name, func(t *testing.T) {
+			err := Verify(tCase.secret, tCase.header, tCase.body, 5*time.Minute, tCase.now)
+			if tCase.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			}
+		})
+	}
+}